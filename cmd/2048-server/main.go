@@ -0,0 +1,57 @@
+// Command 2048-server hosts games over a JSON HTTP API, see package server.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"2048/server"
+	"2048/storage"
+)
+
+func main() {
+	cfg := server.DefaultConfig()
+	addr := flag.String("addr", ":8048", "address to listen on")
+	flag.DurationVar(&cfg.IdleTimeout, "idle", cfg.IdleTimeout, "drop games unused for this long")
+	flag.IntVar(&cfg.MaxSessions, "max-games", cfg.MaxSessions, "maximum number of games in progress")
+	configDir := flag.String("config-dir", "", "read the board size and spawn odds of new games from the settings.json in this directory")
+	flag.Parse()
+
+	if *configDir != "" {
+		// Same file as the desktop and terminal games, see storage.Open
+		prefs, err := (&storage.Store{Dir: *configDir}).LoadSettings()
+		if err != nil {
+			log.Fatal(err)
+		}
+		cfg.Game = prefs
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	srv := server.New(cfg)
+	go srv.Expire(ctx, time.Minute)
+
+	httpSrv := &http.Server{
+		Addr:              *addr,
+		Handler:           srv,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		// Give in-flight requests a moment to finish
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpSrv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("serving 2048 on %s", *addr)
+	if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
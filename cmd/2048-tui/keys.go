@@ -0,0 +1,73 @@
+package main
+
+// Keys produced by parseKeys besides plain characters, which are
+// returned as themselves (e.g. "a").
+const (
+	keyLeft   = "left"
+	keyRight  = "right"
+	keyUp     = "up"
+	keyDown   = "down"
+	keyEnter  = "enter"
+	keyEscape = "esc"
+	keyCtrlC  = "ctrl+c"
+	keyCtrlY  = "ctrl+y"
+	keyCtrlZ  = "ctrl+z"
+
+	// Reported by the terminal when its window gains or loses focus,
+	// see enterScreen.
+	keyFocusIn  = "focus-in"
+	keyFocusOut = "focus-out"
+)
+
+// parseKeys splits a chunk of raw terminal input into key names.
+// Arrow keys arrive as escape sequences: ESC [ A..D, or ESC O A..D
+// when the terminal is in application cursor mode. Focus changes arrive
+// as ESC [ I and ESC [ O.
+func parseKeys(b []byte) []string {
+	var keys []string
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; {
+		case c == 0x1b:
+			if i+2 < len(b) && (b[i+1] == '[' || b[i+1] == 'O') {
+				if key, ok := arrowKeys[b[i+2]]; ok {
+					keys = append(keys, key)
+					i += 2
+					continue
+				}
+			}
+			if i+2 < len(b) && b[i+1] == '[' {
+				if key, ok := focusKeys[b[i+2]]; ok {
+					keys = append(keys, key)
+					i += 2
+					continue
+				}
+			}
+			keys = append(keys, keyEscape)
+		case c == '\r' || c == '\n':
+			keys = append(keys, keyEnter)
+		case c == 0x03:
+			keys = append(keys, keyCtrlC)
+		case c == 0x19:
+			keys = append(keys, keyCtrlY)
+		case c == 0x1a:
+			keys = append(keys, keyCtrlZ)
+		case c >= 0x20 && c < 0x7f:
+			keys = append(keys, string(rune(c)))
+		}
+	}
+	return keys
+}
+
+// arrowKeys maps the final byte of an arrow escape sequence to its key.
+var arrowKeys = map[byte]string{
+	'A': keyUp,
+	'B': keyDown,
+	'C': keyRight,
+	'D': keyLeft,
+}
+
+// focusKeys maps the final byte of a focus escape sequence to its key.
+var focusKeys = map[byte]string{
+	'I': keyFocusIn,
+	'O': keyFocusOut,
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"arrows", "\x1b[A\x1b[B\x1b[C\x1b[D", []string{keyUp, keyDown, keyRight, keyLeft}},
+		{"application arrows", "\x1bOD", []string{keyLeft}},
+		{"letters", "wasd", []string{"w", "a", "s", "d"}},
+		{"lone escape", "\x1b", []string{keyEscape}},
+		{"unknown sequence", "\x1b[Zq", []string{keyEscape, "[", "Z", "q"}},
+		{"focus", "\x1b[O\x1b[Iw", []string{keyFocusOut, keyFocusIn, "w"}},
+		{"control keys", "\r\x03\x19\x1a", []string{keyEnter, keyCtrlC, keyCtrlY, keyCtrlZ}},
+		{"ignored bytes", "\x00\x7f", nil},
+	}
+
+	for _, c := range cases {
+		got := parseKeys([]byte(c.input))
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: parseKeys(%q) = %q; want %q", c.name, c.input, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,403 @@
+// Command 2048-tui plays 2048 in a terminal, sharing saves and high scores
+// with the windowed game.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"2048/engine"
+	"2048/replay"
+	"2048/settings"
+	"2048/storage"
+	"2048/theme"
+
+	"golang.org/x/term"
+)
+
+// scene is the screen currently shown, mirroring the ui package's scenes.
+type scene int
+
+const (
+	sceneMenu scene = iota
+	scenePlay
+	sceneWin
+	sceneGameOver
+)
+
+// boardSizes lists the square board sizes offered in the menu.
+var boardSizes = []int{3, engine.DefaultGridN, 5, 6, 8}
+
+// app holds the state of the terminal frontend.
+type app struct {
+	scene     scene
+	game      *engine.Game
+	replay    *replay.Replay    // recording of the game, nil if not recorded
+	sizeIndex int               // index into boardSizes
+	prefs     settings.Settings // shared with the windowed game
+	theme     theme.Theme       // colors of the tiles, from prefs
+	store     *storage.Store    // nil if the config dir is unavailable
+	scores    *storage.Scores   // never nil
+	lastInput time.Time         // used to count the time spent playing
+	unfocused bool              // the terminal is in the background, which stops the clock
+	quit      bool
+}
+
+func main() {
+	size := flag.Int("size", 0, "board size for new games (default from the settings)")
+	flag.Parse()
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		log.Fatal("2048-tui must be run in a terminal")
+	}
+
+	a := newApp(*size)
+	if err := a.run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newApp loads the shared saves and scores.
+// A size of 0 picks the one of the settings, if it is square.
+func newApp(size int) *app {
+	a := &app{scene: sceneMenu, sizeIndex: 1, scores: &storage.Scores{}, prefs: settings.Default(), theme: theme.Classic}
+	defer a.selectSize(size)
+
+	store, err := storage.Open()
+	if err != nil {
+		// Keep playing without persistence
+		log.Println(err)
+		return a
+	}
+	a.store = store
+
+	// A broken file falls back to the defaults
+	a.prefs, err = store.LoadSettings()
+	if err != nil {
+		log.Println(err)
+	}
+	themes, err := store.LoadThemes()
+	if err != nil {
+		log.Println(err)
+	}
+	a.theme = theme.Find(append(slices.Clone(theme.Builtin), themes...), a.prefs.Theme)
+
+	// A broken table is reset rather than stopping the game from starting
+	scores, err := store.LoadScores()
+	if err != nil {
+		log.Println(err)
+	}
+	a.scores = scores
+	return a
+}
+
+// selectSize picks the board size offered first in the menu.
+func (a *app) selectSize(size int) {
+	if size == 0 && a.prefs.Rows == a.prefs.Columns {
+		size = a.prefs.Rows
+	}
+	for i, s := range boardSizes {
+		if s == size {
+			a.sizeIndex = i
+		}
+	}
+}
+
+// run switches the terminal to raw mode and processes keys until the player quits.
+func (a *app) run() error {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("entering raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	out := bufio.NewWriter(os.Stdout)
+	fmt.Fprint(out, enterScreen)
+	defer func() {
+		fmt.Fprint(out, leaveScreen)
+		out.Flush()
+	}()
+
+	// NOTE: Keys are read on their own goroutine, so the clock of a time
+	// attack keeps running, and showing, while the player thinks.
+	input, readErr := make(chan []byte), make(chan error, 1)
+	go func() {
+		for {
+			buf := make([]byte, 64)
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				readErr <- err
+				return
+			}
+			input <- buf[:n]
+		}
+	}()
+	ticker := time.NewTicker(clockTick)
+	defer ticker.Stop()
+
+	redraw := true
+	for !a.quit {
+		if redraw {
+			a.draw(out)
+			if err := out.Flush(); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case b := <-input:
+			for _, key := range parseKeys(b) {
+				a.update(key)
+			}
+			redraw = true
+		case now := <-ticker.C:
+			redraw = a.tick(now)
+		case err := <-readErr:
+			a.saveGame()
+			return err
+		}
+	}
+	return nil
+}
+
+// clockTick is how often the countdown of a time attack is updated, and
+// maxTickGap the most a time attack is charged at once.
+const (
+	clockTick  = time.Second / 4
+	maxTickGap = 4 * clockTick
+)
+
+// update applies one key press to the current scene.
+func (a *app) update(key string) {
+	switch key {
+	case keyFocusIn, keyFocusOut:
+		a.setFocus(key == keyFocusIn, time.Now())
+		return
+	}
+
+	switch a.scene {
+	case sceneMenu:
+		a.updateMenu(key)
+	case scenePlay:
+		a.updatePlay(key)
+	case sceneWin:
+		a.updateWin(key)
+	case sceneGameOver:
+		a.updateGameOver(key)
+	}
+}
+
+func (a *app) updateMenu(key string) {
+	switch key {
+	case keyLeft, "h", "a":
+		a.sizeIndex = max(a.sizeIndex-1, 0)
+	case keyRight, "l", "d":
+		a.sizeIndex = min(a.sizeIndex+1, len(boardSizes)-1)
+	case keyEnter, "n":
+		a.newGame()
+	case "c":
+		a.loadGame()
+	case "q", keyEscape, keyCtrlC:
+		a.quit = true
+	}
+}
+
+// directionKeys maps arrows, WASD and hjkl to moves.
+var directionKeys = map[string]engine.Direction{
+	keyLeft: engine.Left, "a": engine.Left, "h": engine.Left,
+	keyRight: engine.Right, "d": engine.Right, "l": engine.Right,
+	keyUp: engine.Up, "w": engine.Up, "k": engine.Up,
+	keyDown: engine.Down, "s": engine.Down, "j": engine.Down,
+}
+
+func (a *app) updatePlay(key string) {
+	a.charge(time.Now())
+
+	switch key {
+	case "q", keyCtrlC:
+		// Save the game and leave, it can be continued later
+		a.saveGame()
+		a.quit = true
+		return
+	case "m", keyEscape:
+		a.saveGame()
+		a.scene = sceneMenu
+		return
+	case "u", keyCtrlZ:
+		if a.game.Undo() && a.replay != nil {
+			a.replay.RecordUndo(a.game.Elapsed)
+		}
+	case "y", keyCtrlY:
+		if a.game.Redo() && a.replay != nil {
+			a.replay.RecordRedo(a.game.Elapsed)
+		}
+	}
+
+	if dir, ok := directionKeys[key]; ok {
+		events, _ := a.game.Play(dir)
+		if a.replay != nil {
+			a.replay.RecordMove(dir, events, a.game.Elapsed)
+		}
+	}
+
+	if a.game.JustWon() {
+		a.scene = sceneWin
+		return
+	}
+	a.checkOver()
+}
+
+// tick runs the clock of a time attack between key presses.
+// Returns true if the screen needs to be redrawn.
+func (a *app) tick(now time.Time) bool {
+	if a.scene != scenePlay || a.game.TimeLimit == 0 || a.unfocused {
+		return false
+	}
+	a.charge(now)
+	a.checkOver()
+	return true
+}
+
+// checkOver ends the game once it is stuck, or out of time or moves.
+func (a *app) checkOver() {
+	if _, over := a.game.Over(); over {
+		a.recordScore()
+		a.deleteSave()
+		a.scene = sceneGameOver
+	}
+}
+
+// charge counts the time spent in this game since the previous key press,
+// tick or focus change, none while the terminal is in the background.
+// NOTE: A time attack is charged on every tick, so a longer gap means the
+// terminal was suspended, which stops its clock like losing focus does.
+func (a *app) charge(now time.Time) {
+	limit := idleLimit
+	if a.game.TimeLimit > 0 {
+		limit = maxTickGap
+	}
+	if !a.unfocused {
+		a.game.Elapsed += min(now.Sub(a.lastInput), limit)
+	}
+	a.lastInput = now
+}
+
+// setFocus starts or stops the clock as the terminal gains or loses focus,
+// as the windowed game does.
+func (a *app) setFocus(focused bool, now time.Time) {
+	if a.scene == scenePlay {
+		a.charge(now) // up to the change
+	}
+	a.unfocused = !focused
+	a.lastInput = now
+}
+
+// idleLimit caps how much time a single pause between keys adds to a game,
+// so leaving the terminal open doesn't inflate the play time.
+const idleLimit = 30 * time.Second
+
+func (a *app) updateWin(key string) {
+	switch key {
+	case "k", keyEnter:
+		// Continue the same game, the win screen won't show up again
+		a.game.KeepGoing()
+		a.startPlaying()
+	case "n":
+		// The won game counts as finished
+		a.recordScore()
+		a.deleteSave()
+		a.newGame()
+	case "q", keyCtrlC:
+		// Save the won game, the choice is offered again when it is continued
+		a.saveGame()
+		a.quit = true
+	}
+}
+
+func (a *app) updateGameOver(key string) {
+	switch key {
+	case "r":
+		a.newGame()
+	case "m", keyEscape:
+		a.scene = sceneMenu
+	case "u":
+		if reason, _ := a.game.Over(); reason != engine.EndTimeUp && a.game.Undo() {
+			// Take back the last move and keep playing, but not a lost race against time
+			if a.replay != nil {
+				a.replay.RecordUndo(a.game.Elapsed)
+			}
+			a.startPlaying()
+		}
+	case "q", keyCtrlC:
+		a.quit = true
+	}
+}
+
+// newGame starts a fresh game with the board size selected in the menu.
+func (a *app) newGame() {
+	size := boardSizes[a.sizeIndex]
+	a.game = engine.NewGame(size, size, a.prefs.GameOptions()...)
+	a.replay = replay.New(a.game)
+	a.startPlaying()
+}
+
+// startPlaying switches to the play scene.
+func (a *app) startPlaying() {
+	a.lastInput = time.Now()
+	a.scene = scenePlay
+}
+
+// loadGame resumes the saved game, if there is one.
+func (a *app) loadGame() {
+	if a.store == nil || !a.store.HasSavedGame() {
+		return
+	}
+	// Keep recording where the saved game left off
+	g, r, err := a.store.LoadProgress()
+	if err != nil {
+		log.Println(err)
+	}
+	if g == nil {
+		return
+	}
+	a.game, a.replay = g, r
+	a.startPlaying()
+	if g.JustWon() {
+		a.scene = sceneWin // saved before the player chose
+	}
+}
+
+// saveGame stores the game in progress, if there is one, including a won
+// game whose player hasn't decided yet whether to keep going.
+func (a *app) saveGame() {
+	if a.store == nil || a.game == nil || a.scene != scenePlay && a.scene != sceneWin {
+		return
+	}
+	if err := a.store.SaveProgress(a.game, a.replay); err != nil {
+		log.Println(err)
+	}
+}
+
+// deleteSave forgets the saved game, e.g. once it is over.
+func (a *app) deleteSave() {
+	if a.store == nil {
+		return
+	}
+	if err := a.store.DeleteProgress(); err != nil {
+		log.Println(err)
+	}
+}
+
+// recordScore adds the finished game to its high-score table and saves it,
+// together with its replay, see storage.RecordGame.
+func (a *app) recordScore() {
+	if err := storage.RecordGame(a.store, a.scores, a.game, a.replay, time.Now()); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+	"time"
+
+	"2048/engine"
+	"2048/settings"
+	"2048/theme"
+)
+
+// ANSI control sequences.
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l\x1b[?1004h" // alternate screen, hide cursor, report focus
+	leaveScreen = "\x1b[?1004l\x1b[?25h\x1b[?1049l" // stop reporting focus, show cursor, main screen
+	clearScreen = "\x1b[H\x1b[2J"
+	resetStyle  = "\x1b[0m"
+)
+
+// Size of a tile in terminal cells.
+const (
+	tileWidth  = 8
+	tileHeight = 3
+)
+
+// style returns the escape sequence for 24-bit background and foreground colors.
+func style(bg, fg color.RGBA) string {
+	return fmt.Sprintf("\x1b[48;2;%d;%d;%dm\x1b[38;2;%d;%d;%dm",
+		bg.R, bg.G, bg.B, fg.R, fg.G, fg.B)
+}
+
+// line writes one line of text followed by a raw-mode line break.
+func line(w io.Writer, format string, args ...any) {
+	fmt.Fprintf(w, format+"\r\n", args...)
+}
+
+// gameOverTitles tell why a game ended, by reason.
+var gameOverTitles = map[engine.EndReason]string{
+	engine.EndStuck:   "Game Over!",
+	engine.EndTimeUp:  "Time's Up!",
+	engine.EndNoMoves: "Out of Moves!",
+}
+
+// draw renders the current scene.
+func (a *app) draw(w io.Writer) {
+	fmt.Fprint(w, clearScreen)
+	switch a.scene {
+	case sceneMenu:
+		a.drawMenu(w)
+	case scenePlay:
+		a.drawHUD(w)
+		drawBoard(w, a.game, &a.theme, a.prefs.Numbers)
+		line(w, "")
+		line(w, "  arrows/WASD/hjkl: move   u: undo   y: redo   m: menu   q: save & quit")
+	case sceneWin:
+		a.drawHUD(w)
+		drawBoard(w, a.game, &a.theme, a.prefs.Numbers)
+		line(w, "")
+		line(w, "  You reached the %d tile!   k: keep going   n: new game   q: quit", a.game.Target)
+	case sceneGameOver:
+		a.drawHUD(w)
+		drawBoard(w, a.game, &a.theme, a.prefs.Numbers)
+		line(w, "")
+		reason, _ := a.game.Over()
+		info := "  " + gameOverTitles[reason] + "   r: retry   m: menu   q: quit"
+		if a.game.UndoCount() > 0 && reason != engine.EndTimeUp {
+			info += "   u: undo"
+		}
+		line(w, "%s", info)
+	}
+}
+
+func (a *app) drawMenu(w io.Writer) {
+	size := boardSizes[a.sizeIndex]
+	line(w, "")
+	line(w, "  2048")
+	line(w, "")
+	line(w, "  Best Score: %d", a.scores.Challenge(a.prefs.Challenge).Best)
+	line(w, "")
+	if a.store != nil && a.store.HasSavedGame() {
+		line(w, "  c: continue")
+	}
+	line(w, "  enter: new game   < %dx%d >", size, size)
+	line(w, "  q: quit")
+}
+
+func (a *app) drawHUD(w io.Writer) {
+	line(w, "")
+	hud := fmt.Sprintf("  SCORE %-8d", a.game.Score)
+	// Games that can't enter a high-score table have no best to beat
+	if t := a.scores.TableFor(a.game); t != nil {
+		hud += fmt.Sprintf(" BEST %-8d", max(t.Best, a.game.Score))
+	}
+	hud += fmt.Sprintf(" UNDO %d", a.game.UndoCount())
+	switch g := a.game; {
+	case g.TimeLimit > 0:
+		hud += fmt.Sprintf("   TIME %s", (g.TimeLeft() + time.Second - 1).Truncate(time.Second))
+	case g.MoveLimit > 0:
+		hud += fmt.Sprintf("   MOVES %d", g.MovesLeft())
+	}
+	if next := a.game.Next; next != 0 {
+		colors := a.theme.Tile(a.game.Rules.Rank(next))
+		hud += "   NEXT " + style(colors.Background, colors.Foreground) +
+			center(settings.FormatTile(next, a.prefs.Numbers), tileWidth) + resetStyle
+	}
+	line(w, "%s", hud)
+	line(w, "")
+}
+
+// drawBoard renders the board as blocks of colored terminal cells, with
+// large values written in the given notation (see settings.Notations).
+func drawBoard(w io.Writer, g *engine.Game, th *theme.Theme, notation string) {
+	for r := range g.Rows {
+		for y := range tileHeight {
+			var b strings.Builder
+			b.WriteString("  ")
+			for c := range g.Columns {
+				v := g.Board[r][c]
+				if v == engine.Wall {
+					b.WriteString(style(th.Widget, th.WidgetText))
+					b.WriteString(strings.Repeat(" ", tileWidth))
+					b.WriteString(resetStyle + " ")
+					continue
+				}
+				colors := th.Tile(g.Rules.Rank(v))
+				b.WriteString(style(colors.Background, colors.Foreground))
+
+				text := ""
+				if v != 0 && y == tileHeight/2 {
+					text = settings.FormatTile(v, notation)
+				}
+				b.WriteString(center(text, tileWidth))
+				b.WriteString(resetStyle)
+				b.WriteString(" ")
+			}
+			line(w, "%s", b.String())
+		}
+		line(w, "")
+	}
+}
+
+// center pads s with spaces to width, keeping it in the middle.
+func center(s string, width int) string {
+	pad := max(width-len(s), 0)
+	return strings.Repeat(" ", pad/2) + s + strings.Repeat(" ", pad-pad/2)
+}
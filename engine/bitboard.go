@@ -0,0 +1,196 @@
+package engine
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Bitboard is a 4x4 board packed into 64 bits for fast simulation.
+// Each cell is a nibble holding the tile's exponent: 0 for an empty cell,
+// 1 for a 2, 2 for a 4, ... up to 15 for 32768. Cell (row, column) lives at
+// nibble 4*row + column, counting from the least significant bits, so every
+// row is one 16-bit word.
+//
+// NOTE: Bitboards always follow the Classic rules. Two 32768 tiles cannot
+// merge on a Bitboard since 65536 doesn't fit in a nibble; this is the only
+// difference from Game.Move.
+type Bitboard uint64
+
+// bitboardN is the only board size a Bitboard can hold.
+const bitboardN = 4
+
+// maxBitboardExp is the largest exponent that fits in a nibble.
+const maxBitboardExp = 15
+
+// Precomputed results of moving every possible row.
+var (
+	rowLeft  [1 << 16]uint16 // row after a left move
+	rowRight [1 << 16]uint16 // row after a right move
+	rowGain  [1 << 16]int32  // score gained by a left move (same as right on the reversed row)
+)
+
+func init() {
+	for row := range 1 << 16 {
+		left, gain := slideMergeRow(uint16(row))
+		rowLeft[row] = left
+		rowGain[row] = gain
+
+		// A right move is a left move on the reversed row
+		rev := reverseRow(uint16(row))
+		right, _ := slideMergeRow(rev)
+		rowRight[row] = reverseRow(right)
+	}
+}
+
+// slideMergeRow moves a packed row to the left with the same rules as
+// slideMergeLine, working on exponents instead of values.
+func slideMergeRow(row uint16) (uint16, int32) {
+	var line [bitboardN]uint16
+	n := 0
+	for c := range bitboardN {
+		if exp := (row >> (4 * c)) & 0xF; exp != 0 {
+			line[n] = exp
+			n++
+		}
+	}
+
+	// Merge neighbours once each, then compact
+	var out [bitboardN]uint16
+	var gain int32
+	w := 0
+	for i := 0; i < n; i++ {
+		if i+1 < n && line[i] == line[i+1] && line[i] < maxBitboardExp {
+			out[w] = line[i] + 1
+			gain += 1 << out[w]
+			i++
+		} else {
+			out[w] = line[i]
+		}
+		w++
+	}
+
+	var packed uint16
+	for c, exp := range out {
+		packed |= exp << (4 * c)
+	}
+	return packed, gain
+}
+
+// reverseRow flips the order of the four nibbles of a row.
+func reverseRow(row uint16) uint16 {
+	return row>>12 | (row>>4)&0x00F0 | (row<<4)&0x0F00 | row<<12
+}
+
+// NewBitboard packs a 4x4 board. Every tile must be 0 or a power of two
+// between 2 and 32768.
+func NewBitboard(board [][]int) (Bitboard, error) {
+	if err := checkBoard(board, bitboardN, bitboardN); err != nil {
+		return 0, err
+	}
+
+	var b Bitboard
+	for r := range bitboardN {
+		for c := range bitboardN {
+			v := board[r][c]
+			if v == 0 {
+				continue
+			}
+			exp := bits.Len(uint(v)) - 1
+			if v < 2 || v&(v-1) != 0 || exp > maxBitboardExp {
+				return 0, fmt.Errorf("engine: tile %d at (%d, %d) doesn't fit a bitboard", v, r, c)
+			}
+			b |= Bitboard(exp) << (4 * (bitboardN*r + c))
+		}
+	}
+	return b, nil
+}
+
+// Board unpacks the bitboard into a regular board.
+func (b Bitboard) Board() [][]int {
+	board := NewBoard(bitboardN, bitboardN)
+	for r := range bitboardN {
+		for c := range bitboardN {
+			if exp := b.exp(r, c); exp != 0 {
+				board[r][c] = 1 << exp
+			}
+		}
+	}
+	return board
+}
+
+// exp returns the exponent stored in a cell.
+func (b Bitboard) exp(row, column int) uint {
+	return uint(b>>(4*(bitboardN*row+column))) & 0xF
+}
+
+// row returns the packed row r.
+func (b Bitboard) row(r int) uint16 {
+	return uint16(b >> (16 * r))
+}
+
+// transpose swaps rows and columns.
+func (b Bitboard) transpose() Bitboard {
+	// NOTE: Swap the off-diagonal nibbles of each 2x2 block, then the
+	// off-diagonal 2x2 blocks themselves.
+	a1 := b & 0xF0F00F0FF0F00F0F
+	a2 := b & 0x0000F0F00000F0F0
+	a3 := b & 0x0F0F00000F0F0000
+	a := a1 | a2<<12 | a3>>12
+	b1 := a & 0xFF00FF0000FF00FF
+	b2 := a & 0x00FF00FF00000000
+	b3 := a & 0x00000000FF00FF00
+	return b1 | b2>>24 | b3<<24
+}
+
+// Move slides and merges the board in the given direction.
+// Returns the new board and the score gain; the board is unchanged
+// (equal to b) if nothing moved.
+func (b Bitboard) Move(dir Direction) (Bitboard, int) {
+	// Up and Down are Left and Right on the transposed board
+	t := b
+	if dir == Up || dir == Down {
+		t = b.transpose()
+	}
+	table := &rowLeft
+	if dir == Right || dir == Down {
+		table = &rowRight
+	}
+
+	var out Bitboard
+	gain := 0
+	for r := range bitboardN {
+		row := t.row(r)
+		out |= Bitboard(table[row]) << (16 * r)
+		if dir == Right || dir == Down {
+			gain += int(rowGain[reverseRow(row)])
+		} else {
+			gain += int(rowGain[row])
+		}
+	}
+
+	if dir == Up || dir == Down {
+		out = out.transpose()
+	}
+	return out, gain
+}
+
+// Empty returns the number of free cells.
+func (b Bitboard) Empty() int {
+	n := 0
+	for i := range bitboardN * bitboardN {
+		if (b>>(4*i))&0xF == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// CanMove returns true if at least one move changes the board.
+func (b Bitboard) CanMove() bool {
+	for _, dir := range Directions {
+		if next, _ := b.Move(dir); next != b {
+			return true
+		}
+	}
+	return false
+}
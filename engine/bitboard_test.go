@@ -0,0 +1,182 @@
+package engine
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+// rowValues unpacks a packed row into tile values.
+func rowValues(row uint16) []int {
+	line := make([]int, bitboardN)
+	for c := range line {
+		if exp := (row >> (4 * c)) & 0xF; exp != 0 {
+			line[c] = 1 << exp
+		}
+	}
+	return line
+}
+
+// TestBitboardRowsMatchSlideMergeLine checks every possible row against slideMergeLine.
+func TestBitboardRowsMatchSlideMergeLine(t *testing.T) {
+	for row := range 1 << 16 {
+		line := rowValues(uint16(row))
+		want, _, wantGain := slideMergeLine(copyLine(line))
+
+		// NOTE: 32768 + 32768 doesn't fit a nibble, see Bitboard.
+		if wantGain >= 1<<(maxBitboardExp+1) {
+			continue
+		}
+
+		got := rowValues(rowLeft[row])
+		if !reflect.DeepEqual(got, want) || int(rowGain[row]) != wantGain {
+			t.Fatalf("row %v: left = %v (+%d); want %v (+%d)",
+				line, got, rowGain[row], want, wantGain)
+		}
+
+		wantRight, _, _ := slideMergeLine(reverse(copyLine(line)))
+		gotRight := rowValues(rowRight[row])
+		if !reflect.DeepEqual(gotRight, reverse(wantRight)) {
+			t.Fatalf("row %v: right = %v; want %v", line, gotRight, reverse(wantRight))
+		}
+	}
+}
+
+func TestBitboardNoOverflow(t *testing.T) {
+	b, err := NewBitboard([][]int{
+		{32768, 32768, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next, gain := b.Move(Left); next != b || gain != 0 {
+		t.Errorf("two 32768 tiles merged on a bitboard: %v (+%d)", next.Board(), gain)
+	}
+}
+
+// randomBoard fills a 4x4 board with a mix of empty cells and small tiles.
+func randomBoard(rng *rand.Rand) [][]int {
+	board := NewBoard(bitboardN, bitboardN)
+	for r := range board {
+		for c := range board[r] {
+			if exp := rng.IntN(12); exp > 0 {
+				board[r][c] = 1 << exp
+			}
+		}
+	}
+	return board
+}
+
+// TestBitboardMatchesGame cross-checks random boards in every direction against Game.Move.
+func TestBitboardMatchesGame(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 10000 {
+		board := randomBoard(rng)
+		b, err := NewBitboard(board)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(b.Board(), board) {
+			t.Fatalf("round trip: %v; want %v", b.Board(), board)
+		}
+
+		g := &Game{Board: board, Rows: bitboardN, Columns: bitboardN}
+		if b.CanMove() != g.CanMove() {
+			t.Fatalf("%v: CanMove() = %v; want %v", board, b.CanMove(), g.CanMove())
+		}
+
+		for _, dir := range Directions {
+			g := &Game{Board: cloneBoard(board), Rows: bitboardN, Columns: bitboardN}
+			moved, wantGain := g.Move(dir)
+
+			next, gain := b.Move(dir)
+			if !reflect.DeepEqual(next.Board(), g.Board) || gain != wantGain || (next != b) != moved {
+				t.Fatalf("%v moved %v: %v (+%d); want %v (+%d)",
+					board, dir, next.Board(), gain, g.Board, wantGain)
+			}
+		}
+	}
+}
+
+func TestBitboardTranspose(t *testing.T) {
+	board := [][]int{
+		{2, 4, 8, 16},
+		{32, 64, 128, 256},
+		{512, 1024, 2048, 4096},
+		{8192, 16384, 32768, 0},
+	}
+	b, err := NewBitboard(board)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := b.transpose().Board()
+	for r := range bitboardN {
+		for c := range bitboardN {
+			if got[r][c] != board[c][r] {
+				t.Fatalf("transpose = %v", got)
+			}
+		}
+	}
+	if b.transpose().transpose() != b {
+		t.Error("transposing twice doesn't give the original board")
+	}
+	if b.Empty() != 1 {
+		t.Errorf("Empty() = %d; want 1", b.Empty())
+	}
+}
+
+func TestNewBitboardInvalid(t *testing.T) {
+	cases := []struct {
+		name  string
+		board [][]int
+	}{
+		{"wrong size", NewBoard(3, 3)},
+		{"not a power of two", [][]int{{3, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}},
+		{"too large", [][]int{{65536, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}},
+		{"one", [][]int{{1, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}}},
+	}
+
+	for _, c := range cases {
+		if _, err := NewBitboard(c.board); err == nil {
+			t.Errorf("%s: NewBitboard(%v) succeeded; want an error", c.name, c.board)
+		}
+	}
+}
+
+// cloneBoard returns a deep copy of a board.
+func cloneBoard(board [][]int) [][]int {
+	out := make([][]int, len(board))
+	for r := range board {
+		out[r] = copyLine(board[r])
+	}
+	return out
+}
+
+var benchBoard = [][]int{
+	{2, 4, 8, 16},
+	{0, 2, 0, 4},
+	{4, 4, 2, 0},
+	{0, 0, 0, 2},
+}
+
+func BenchmarkBitboardMove(b *testing.B) {
+	bb, _ := NewBitboard(benchBoard)
+	for range b.N {
+		for _, dir := range Directions {
+			bb.Move(dir)
+		}
+	}
+}
+
+func BenchmarkGameMove(b *testing.B) {
+	for range b.N {
+		for _, dir := range Directions {
+			g := &Game{Board: cloneBoard(benchBoard), Rows: bitboardN, Columns: bitboardN}
+			g.Move(dir)
+		}
+	}
+}
@@ -3,5 +3,11 @@ package engine
 const (
 	ScreenWidth  = 800
 	ScreenHeight = 800
-	GridN        = 4
+
+	DefaultGridN = 4 // default number of rows and columns
+	MinGridN     = 2 // smallest supported number of rows or columns
+
+	DefaultTarget = 2048 // tile value that wins the game
+
+	DefaultFourChance = 0.1 // probability that a spawned tile is a 4 rather than a 2
 )
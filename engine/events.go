@@ -0,0 +1,54 @@
+package engine
+
+// EventKind tells what happened to a tile during a turn.
+type EventKind int
+
+const (
+	EventSlide EventKind = iota // a tile moved From -> To
+	EventMerge                  // a tile moved From -> To and combined into a tile of Value there
+	EventSpawn                  // a new tile of Value appeared at To
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventSlide:
+		return "slide"
+	case EventMerge:
+		return "merge"
+	case EventSpawn:
+		return "spawn"
+	}
+	return "invalid"
+}
+
+// Cell is a position on the board.
+type Cell struct {
+	Row    int
+	Column int
+}
+
+// Event describes one tile transition of a turn.
+// NOTE: Both tiles of a merge get their own EventMerge, sharing To and Value;
+// the one that stays in place has From == To. Tiles that don't move or merge
+// produce no event.
+type Event struct {
+	Kind  EventKind
+	From  Cell // source cell (unused for EventSpawn)
+	To    Cell // destination cell
+	Value int  // tile value before the move for EventSlide, the resulting value otherwise
+}
+
+// lineCell maps a position within the i-th line extracted for dir back to
+// the board cell it came from.
+func (g *Game) lineCell(dir Direction, i, pos int) Cell {
+	switch dir {
+	case Right:
+		return Cell{i, g.Columns - 1 - pos}
+	case Up:
+		return Cell{pos, i}
+	case Down:
+		return Cell{g.Rows - 1 - pos, i}
+	default: // Left
+		return Cell{i, pos}
+	}
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// Fibonacci are the rules of the Fibonacci variant: two neighbouring
+// Fibonacci numbers merge into the next one (1+1=2, 1+2=3, 2+3=5, ...),
+// 1s and 2s spawn, and the game is won by making 2584.
+var Fibonacci Rules = fibonacciRules{}
+
+// FibonacciTarget is the tile that wins a Fibonacci game by default.
+const FibonacciTarget = 2584
+
+// fibonacci lists the tiles of the Fibonacci variant, 1, 2, 3, 5, 8, ...
+// up to the largest that fits in an int.
+var fibonacci = func() []int {
+	seq := []int{1, 2}
+	for {
+		a, b := seq[len(seq)-2], seq[len(seq)-1]
+		if a > math.MaxInt-b {
+			return seq
+		}
+		seq = append(seq, a+b)
+	}
+}()
+
+type fibonacciRules struct{}
+
+func (fibonacciRules) Name() string { return "fibonacci" }
+
+// CanMerge reports whether a and b are both 1 or follow one another in the
+// Fibonacci sequence, in either order.
+func (fibonacciRules) CanMerge(a, b int) bool {
+	if a == 1 && b == 1 {
+		return true
+	}
+	i, ok := slices.BinarySearch(fibonacci, a)
+	j, ok2 := slices.BinarySearch(fibonacci, b)
+	if !ok || !ok2 || max(i, j)+1 >= len(fibonacci) {
+		return false // unknown tiles, or the sum would overflow
+	}
+	return i-j == 1 || j-i == 1
+}
+
+func (fibonacciRules) Merge(a, b int) int { return a + b }
+
+func (fibonacciRules) Score(v int) int { return v }
+
+func (fibonacciRules) Spawn(rng *rand.Rand, twoChance float64) int {
+	if rng.Float64() < twoChance {
+		return 2
+	}
+	return 1
+}
+
+func (fibonacciRules) SpawnOdds(twoChance float64) []Odds {
+	return []Odds{{1, 1 - twoChance}, {2, twoChance}}
+}
+
+func (fibonacciRules) Won(board [][]int, target int) bool {
+	return maxTile(board) >= target
+}
+
+func (fibonacciRules) Target() int { return FibonacciTarget }
+
+// Rank is the index of v in the Fibonacci sequence: 1 for a 1, 2 for a 2,
+// 3 for a 3, 4 for a 5 and so on. Other values get the rank of the next
+// smaller Fibonacci number.
+func (fibonacciRules) Rank(v int) int {
+	i, ok := slices.BinarySearch(fibonacci, v)
+	if ok {
+		return i + 1
+	}
+	return i // i is where v would be inserted, past the smaller number
+}
+
+func (fibonacciRules) Movement() Movement { return Movement{} }
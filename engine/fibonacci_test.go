@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFibonacciMergeLine(t *testing.T) {
+	cases := []struct {
+		name       string
+		input      []int // A pre-slid line
+		want       []int // The line after merging, but before the final slide
+		scoreGain  int
+		wantMerged bool
+	}{
+		{
+			"ones merge",
+			[]int{1, 1, 3, 8},
+			[]int{2, 0, 3, 8},
+			2,
+			true,
+		},
+		{
+			"consecutive merge",
+			[]int{1, 2, 0, 0},
+			[]int{3, 0, 0, 0},
+			3,
+			true,
+		},
+		{
+			"consecutive merge reversed",
+			[]int{8, 5, 13, 0},
+			[]int{13, 0, 13, 0},
+			13,
+			true,
+		},
+		{
+			"double merge",
+			[]int{2, 3, 5, 8},
+			[]int{5, 0, 13, 0}, // NOTE: becomes [5, 13, 0, 0] only AFTER a final slide
+			18,
+			true,
+		},
+		{
+			"equal tiles don't merge",
+			[]int{2, 2, 3, 3},
+			[]int{2, 5, 0, 3},
+			5,
+			true,
+		},
+		{
+			"no merge",
+			[]int{1, 3, 8, 21},
+			[]int{1, 3, 8, 21},
+			0,
+			false,
+		},
+		{
+			"no chain reaction merge",
+			[]int{1, 1, 3, 0},
+			[]int{2, 0, 3, 0}, // NOTE: Should not become [5, 0, 0, 0]
+			2,
+			true,
+		},
+	}
+
+	for _, c := range cases {
+		got, gain, merged := mergeLineWith(Fibonacci, c.input)
+		if !reflect.DeepEqual(got, c.want) || gain != c.scoreGain || merged != c.wantMerged {
+			t.Errorf("%s: mergeLineWith(Fibonacci, %v) = %v, %d, %v; want %v, %d, %v",
+				c.name, c.input, got, gain, merged, c.want, c.scoreGain, c.wantMerged)
+		}
+	}
+}
+
+func TestFibonacciRules(t *testing.T) {
+	g := NewGame(4, 4, WithSeed(1), WithRules(Fibonacci))
+	if g.Target != FibonacciTarget {
+		t.Errorf("Target = %d; want %d", g.Target, FibonacciTarget)
+	}
+	for _, row := range g.Board {
+		for _, v := range row {
+			if v != 0 && v != 1 && v != 2 {
+				t.Errorf("spawned a %d; want 1s and 2s", v)
+			}
+		}
+	}
+
+	// Only the 1 and the 2 can merge, which a move in either direction reaches
+	g = &Game{Board: [][]int{{1, 2}, {5, 13}}, Rows: 2, Columns: 2, Rules: Fibonacci}
+	if !g.CanMove() {
+		t.Error("CanMove() = false; want true")
+	}
+	g.Board = [][]int{{1, 3}, {5, 13}}
+	if g.CanMove() {
+		t.Error("CanMove() = true on a board without neighbouring Fibonacci numbers")
+	}
+
+	if last := fibonacci[len(fibonacci)-1]; Fibonacci.CanMerge(last, fibonacci[len(fibonacci)-2]) {
+		t.Errorf("CanMerge allowed %d to overflow", last)
+	}
+
+	for v, want := range map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 5: 4, 8: 5, 4: 3, 2584: 17} {
+		if got := Fibonacci.Rank(v); got != want {
+			t.Errorf("Rank(%d) = %d; want %d", v, got, want)
+		}
+	}
+}
+
+func TestFibonacciSpawnOdds(t *testing.T) {
+	want := []Odds{{1, 1 - 0.1}, {2, 0.1}}
+	if got := (fibonacciRules{}).SpawnOdds(0.1); !reflect.DeepEqual(got, want) {
+		t.Errorf("SpawnOdds(0.1) = %v; want %v", got, want)
+	}
+}
@@ -1,5 +1,11 @@
 package engine
 
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
 // Direction represents a movie direction in the game
 type Direction int
 
@@ -10,70 +16,323 @@ const (
 	Down
 )
 
+// Directions lists every direction, in the order of their values.
+var Directions = []Direction{Left, Up, Right, Down}
+
+func (d Direction) String() string {
+	switch d {
+	case Left:
+		return "left"
+	case Up:
+		return "up"
+	case Right:
+		return "right"
+	case Down:
+		return "down"
+	}
+	return "invalid"
+}
+
+// ParseDirection is the inverse of Direction.String.
+func ParseDirection(s string) (Direction, error) {
+	for _, d := range Directions {
+		if d.String() == s {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("engine: unknown direction %q", s)
+}
+
 // Game holds the state of a 2048 game
 type Game struct {
-	Board [GridN][GridN]int // 4 * 4 grid of tiles
-	Score int               // accumulated score
+	Board   [][]int       // Rows * Columns grid of tiles, indexed as Board[row][column]
+	Rows    int           // number of rows on the board
+	Columns int           // number of columns on the board
+	Score   int           // accumulated score
+	Moves   int           // number of turns played
+	Seed    uint64        // seed the random source was created from
+	Elapsed time.Duration // time spent playing, advanced by the frontend
+
+	Target    int  // tile value that wins the game
+	Won       bool // the target tile has been reached at least once
+	Continued bool // the player chose to keep going after winning
+	Assisted  bool // a solver suggested or played moves, set by the frontend
+
+	FourChance float64 // probability that a spawned tile is a 4
+	Rules      Rules   // how tiles merge, score and spawn, Classic by default
+	Next       int     // value of the next spawn if the rules preview it (Movement.Preview), else 0
+
+	TimeLimit time.Duration // Elapsed at which the game ends, 0 for none
+	MoveLimit int           // number of turns after which the game ends, 0 for none
+
+	src     *rand.PCG  // random source, its state fully determines future spawns
+	rng     *rand.Rand // convenience wrapper around src
+	history history    // undo/redo stacks
+
+	walls       []Cell // walls to place by NewGame, see WithWalls
+	randomWalls int    // number of random walls to place by NewGame
 }
 
-// NewGame initializes a new game with two tiles spawned.
-func NewGame() *Game {
-	g := &Game{}
+// Option customizes a Game created by NewGame.
+type Option func(*Game)
+
+// WithSeed makes the game draw its spawns from a source seeded with seed,
+// so the same seed and the same moves always reproduce the same game.
+func WithSeed(seed uint64) Option {
+	return func(g *Game) {
+		g.Seed = seed
+	}
+}
 
-	SpawnTile(&g.Board)
-	SpawnTile(&g.Board)
+// WithTarget sets the tile value that wins the game (Rules.Target by default).
+func WithTarget(target int) Option {
+	return func(g *Game) {
+		g.Target = target
+	}
+}
+
+// WithFourChance sets the probability that a spawned tile is a 4 rather
+// than a 2 (DefaultFourChance by default), e.g. for practice games.
+func WithFourChance(p float64) Option {
+	return func(g *Game) {
+		g.FourChance = p
+	}
+}
+
+// rules returns the rules of the game, Classic if none were set,
+// e.g. for a Game built as a struct literal.
+func (g *Game) rules() Rules {
+	if g.Rules == nil {
+		return Classic
+	}
+	return g.Rules
+}
+
+// WithRules plays the game with the given rules (Classic by default).
+func WithRules(r Rules) Option {
+	return func(g *Game) {
+		g.Rules = r
+	}
+}
+
+// NewGame initializes a new rows * columns game with two tiles spawned.
+// Without WithSeed, a random seed is picked (and stored in Game.Seed).
+// It panics if either dimension is smaller than MinGridN.
+func NewGame(rows, columns int, opts ...Option) *Game {
+	if rows < MinGridN || columns < MinGridN {
+		panic(fmt.Sprintf("engine: invalid board size %dx%d", rows, columns))
+	}
+
+	g := &Game{
+		Board:   NewBoard(rows, columns),
+		Rows:    rows,
+		Columns: columns,
+		Seed:    rand.Uint64(),
+		Target:  -1, // the rules' target unless set
+		history: history{limit: DefaultHistoryLimit},
+
+		FourChance: DefaultFourChance,
+		Rules:      Classic,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.Target < 0 {
+		g.Target = g.rules().Target()
+	}
+	g.placeWalls()
+	g.src = newSource(g.Seed)
+	g.rng = rand.New(g.src)
+	if g.rules().Movement().Preview {
+		g.Next = g.rules().Spawn(g.rng, g.FourChance)
+	}
+
+	g.SpawnTile()
+	g.SpawnTile()
 	return g
 }
 
+// newSource creates the PCG source used for a given seed.
+func newSource(seed uint64) *rand.PCG {
+	// NOTE: PCG takes two words of seed; derive the second one from the first
+	// so a single uint64 is enough to describe a game.
+	return rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)
+}
+
+// SpawnTile places a new tile on a random empty cell using the game's own
+// random source. Returns false if the board is full.
+func (g *Game) SpawnTile() bool {
+	_, ok := g.spawn()
+	return ok
+}
+
+// spawn places a new tile like SpawnTile and describes it as an event.
+func (g *Game) spawn() (Event, bool) {
+	return g.spawnOn(emptyCells(g.Board))
+}
+
+// spawnAfter places the new tile of a turn moved in dir where the rules'
+// SpawnPolicy puts it, like spawn.
+func (g *Game) spawnAfter(dir Direction) (Event, bool) {
+	return g.spawnOn(g.SpawnCells(dir))
+}
+
+// SpawnCells lists the cells the new tile of a turn can land on, once the
+// board has moved in dir.
+func (g *Game) SpawnCells(dir Direction) []Cell {
+	if g.rules().Movement().Spawn == SpawnOppositeEdge {
+		// NOTE: A line that moved one step always frees its cell on that
+		// edge, but a full slide may only merge, leaving the edge full
+		if cells := edgeCells(g.Board, dir); len(cells) > 0 {
+			return cells
+		}
+	}
+	return emptyCells(g.Board)
+}
+
+// SpawnOdds lists the values the next new tile can take along with their
+// probabilities: only Next if the rules preview it and it is drawn.
+func (g *Game) SpawnOdds() []Odds {
+	r := g.rules()
+	if r.Movement().Preview && g.Next != 0 {
+		return []Odds{{g.Next, 1}}
+	}
+	return r.SpawnOdds(g.FourChance)
+}
+
+// spawnOn places a new tile on one of the given empty cells.
+func (g *Game) spawnOn(cells []Cell) (Event, bool) {
+	cell, value, ok := spawnTile(g.Board, g.rng, cells, g.nextTile)
+	return Event{Kind: EventSpawn, To: cell, Value: value}, ok
+}
+
+// nextTile draws the value of a new tile from the rules. With
+// Movement.Preview, it hands out Next and draws the one after it instead.
+func (g *Game) nextTile() int {
+	r := g.rules()
+	if !r.Movement().Preview {
+		return r.Spawn(g.rng, g.FourChance)
+	}
+	v := g.Next
+	if v == 0 {
+		v = r.Spawn(g.rng, g.FourChance) // not drawn yet, e.g. for a struct literal
+	}
+	g.Next = r.Spawn(g.rng, g.FourChance)
+	return v
+}
+
+// NewBoard allocates an empty board with the given dimensions.
+func NewBoard(rows, columns int) [][]int {
+	board := make([][]int, rows)
+	for row := range board {
+		board[row] = make([]int, columns)
+	}
+	return board
+}
+
+// Play runs a full turn: it applies the move, spawns a new tile if the board
+// changed, and records the turn so it can be undone.
+// Returns the events of the turn (empty if nothing moved, or once a limit
+// is reached, see Over), the spawn being the last one, and score gain.
+func (g *Game) Play(dir Direction) (events []Event, gain int) {
+	if _, ok := g.limitReached(); ok {
+		return nil, 0
+	}
+	before := g.snapshot()
+	if events, gain = g.MoveEvents(dir); len(events) == 0 {
+		return nil, 0
+	}
+
+	if spawned, ok := g.spawnAfter(dir); ok {
+		events = append(events, spawned)
+	}
+	g.Moves++
+	g.record(before)
+
+	// NOTE: Won is never reset, not even by Undo, so reaching the target
+	// a second time doesn't count as a new win.
+	if !g.Won && g.Target > 0 && g.rules().Won(g.Board, g.Target) {
+		g.Won = true
+	}
+	return events, gain
+}
+
+// JustWon reports whether the target was reached and the player hasn't
+// decided yet whether to keep going.
+func (g *Game) JustWon() bool {
+	return g.Won && !g.Continued
+}
+
+// KeepGoing lets the player continue after winning;
+// JustWon stays false for the rest of the game.
+func (g *Game) KeepGoing() {
+	g.Continued = true
+}
+
 // Move applies a slide/merge in the given direction.
 // Returns moved=true if any tile moved or merged (changed), and score gain.
 func (g *Game) Move(dir Direction) (moved bool, gain int) {
-	var lines [GridN][]int
+	events, gain := g.MoveEvents(dir)
+	return len(events) > 0, gain
+}
+
+// MoveEvents applies a slide/merge like Move, and reports every tile that
+// slid or merged. Returns no events if the board didn't change.
+func (g *Game) MoveEvents(dir Direction) (events []Event, gain int) {
+	var lines [][]int
 
 	// NOTE: Extract rows or columns into lines based on direction.
 	// It adjusts some logics so every move can be treated as a left move,
 	switch dir {
 	case Left:
 		// Left: copy rows directly
-		for row := range GridN {
-			lines[row] = copyLine(g.Board[row][:])
+		for row := range g.Rows {
+			lines = append(lines, copyLine(g.Board[row]))
 		}
 	case Right:
 		// Right: copy rows and reverse them
-		for row := range GridN {
-			lines[row] = reverse(copyLine(g.Board[row][:]))
+		for row := range g.Rows {
+			lines = append(lines, reverse(copyLine(g.Board[row])))
 		}
 	case Up:
 		// Up: copy columns into rows
-		for column := range GridN {
-			col := make([]int, GridN)
-			for row := range GridN {
+		for column := range g.Columns {
+			col := make([]int, g.Rows)
+			for row := range g.Rows {
 				col[row] = g.Board[row][column]
 			}
-			lines[column] = col
+			lines = append(lines, col)
 		}
 	case Down:
 		// Down: copy columns and reverse t
-		for column := range GridN {
-			col := make([]int, GridN)
-			for row := range GridN {
-				col[row] = g.Board[GridN-1-row][column] // reverse column for down
+		for column := range g.Columns {
+			col := make([]int, g.Rows)
+			for row := range g.Rows {
+				col[row] = g.Board[g.Rows-1-row][column] // reverse column for down
 			}
-			lines[column] = col
+			lines = append(lines, col)
 		}
 	}
 
 	// NOTE: Process each line
 	// 1. Slides all non-zero left.
-	// 2. Merges identical neighbors (doubling one, zeroing the other, adding to gain).
+	// 2. Merges the neighbors the rules allow (replacing one, zeroing the other, adding to gain).
 	// 3. Slides again to collapse the gaps.
 	for i, line := range lines {
-		newLine, movedLine, gainLine := slideMergeLine(line)
-		if movedLine {
-			moved = true
-		}
+		newLine, moves, gainLine := slideMergeLineMovesWith(g.rules(), line)
 		gain += gainLine
 
+		// Translate line positions back into board cells
+		for _, m := range moves {
+			from, to := g.lineCell(dir, i, m.from), g.lineCell(dir, i, m.to)
+			switch {
+			case m.merged:
+				events = append(events, Event{Kind: EventMerge, From: from, To: to, Value: newLine[m.to]})
+			case from != to:
+				events = append(events, Event{Kind: EventSlide, From: from, To: to, Value: line[m.from]})
+			}
+		}
+
 		// Write back to board
 		// NOTE: Take each transformed slice and shove it back into the board
 		// in the correct orientation (undoing the reserce if needed).
@@ -86,7 +345,7 @@ func (g *Game) Move(dir Direction) (moved bool, gain int) {
 		case Right:
 			// Right: write new line back to the same row, reversed
 			for c, v := range newLine {
-				g.Board[i][GridN-1-c] = v // reverse back to original row
+				g.Board[i][g.Columns-1-c] = v // reverse back to original row
 			}
 		case Up:
 			// Up: write new line back to the same column
@@ -96,16 +355,42 @@ func (g *Game) Move(dir Direction) (moved bool, gain int) {
 		case Down:
 			// Down: write new line back to the same column, reversed
 			for r, v := range newLine {
-				g.Board[GridN-1-r][i] = v // reverse back to original column
+				g.Board[g.Rows-1-r][i] = v // reverse back to original column
 			}
 		}
 	}
 
-	if moved {
-		g.Score += gain
+	if len(events) == 0 {
+		return nil, 0
+	}
+
+	g.Score += gain
+	return events, gain
+}
+
+// Clone returns a copy of the game that can be played without affecting g,
+// e.g. to look ahead in a search. The undo history is not copied.
+func (g *Game) Clone() *Game {
+	c := *g
+	c.Board = NewBoard(g.Rows, g.Columns)
+	for row := range g.Rows {
+		copy(c.Board[row], g.Board[row])
 	}
+	c.history = history{limit: g.history.limit, reroll: g.history.reroll}
 
-	return moved, gain
+	if g.src != nil {
+		// NOTE: PCG state round-trips through MarshalBinary without error.
+		state, _ := g.src.MarshalBinary()
+		c.src = &rand.PCG{}
+		_ = c.src.UnmarshalBinary(state)
+		c.rng = rand.New(c.src)
+	}
+	return &c
+}
+
+// MaxTile returns the highest tile value on the board.
+func (g *Game) MaxTile() int {
+	return maxTile(g.Board)
 }
 
 // copyLine clones a slice of ints.
@@ -126,29 +411,29 @@ func reverse(line []int) []int {
 
 // CanMove returns true if at least one move in possible
 func (g *Game) CanMove() bool {
-	// any empty cell?
-	for row := range GridN {
-		for column := range GridN {
-			if g.Board[row][column] == 0 {
-				// Empty cell is found
+	// any empty cell a tile can slide into?
+	// NOTE: Walls can box in an empty cell, which then never takes a tile.
+	for row := range g.Rows {
+		for column := range g.Columns {
+			if g.Board[row][column] == 0 && hasTileNeighbour(g.Board, row, column) {
 				return true
 			}
 		}
 	}
 
-	// If any adjacent cells are equal, we can merge,
-	// so there will be a move possible
-	for row := range GridN {
-		for column := 0; column < GridN-1; column++ {
-			if g.Board[row][column] == g.Board[row][column+1] {
+	// If any adjacent cells can merge, in either direction,
+	// there will be a move possible
+	for row := range g.Rows {
+		for column := 0; column < g.Columns-1; column++ {
+			if g.canMerge(g.Board[row][column], g.Board[row][column+1]) {
 				return true
 			}
 		}
 	}
 
-	for column := range GridN {
-		for row := 0; row < GridN-1; row++ {
-			if g.Board[row][column] == g.Board[row+1][column] {
+	for column := range g.Columns {
+		for row := 0; row < g.Rows-1; row++ {
+			if g.canMerge(g.Board[row][column], g.Board[row+1][column]) {
 				return true
 			}
 		}
@@ -156,3 +441,13 @@ func (g *Game) CanMove() bool {
 
 	return false
 }
+
+// canMerge reports whether two neighbouring tiles merge when moved towards
+// one another, whichever way the move goes.
+func (g *Game) canMerge(a, b int) bool {
+	if a == Wall || b == Wall {
+		return false
+	}
+	r := g.rules()
+	return r.CanMerge(a, b) || r.CanMerge(b, a)
+}
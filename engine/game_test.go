@@ -0,0 +1,267 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMoveNonSquare(t *testing.T) {
+	cases := []struct {
+		name  string
+		dir   Direction
+		board [][]int
+		want  [][]int
+		moved bool
+		gain  int
+	}{
+		{
+			"left on 2x3",
+			Left,
+			[][]int{{0, 2, 2}, {4, 0, 4}},
+			[][]int{{4, 0, 0}, {8, 0, 0}},
+			true,
+			12,
+		},
+		{
+			"right on 2x3",
+			Right,
+			[][]int{{2, 2, 4}, {0, 4, 0}},
+			[][]int{{0, 4, 4}, {0, 0, 4}},
+			true,
+			4,
+		},
+		{
+			"up on 3x2",
+			Up,
+			[][]int{{0, 2}, {2, 2}, {2, 4}},
+			[][]int{{4, 4}, {0, 4}, {0, 0}},
+			true,
+			8,
+		},
+		{
+			"down on 3x2",
+			Down,
+			[][]int{{2, 0}, {4, 0}, {8, 2}},
+			[][]int{{2, 0}, {4, 0}, {8, 2}},
+			false,
+			0,
+		},
+	}
+
+	for _, c := range cases {
+		g := &Game{Board: c.board, Rows: len(c.board), Columns: len(c.board[0])}
+		moved, gain := g.Move(c.dir)
+		if !reflect.DeepEqual(g.Board, c.want) || moved != c.moved || gain != c.gain {
+			t.Errorf("%s: Move(%v) = %v, %v, %d; want %v, %v, %d",
+				c.name, c.dir, g.Board, moved, gain, c.want, c.moved, c.gain)
+		}
+	}
+}
+
+func TestNewGameSize(t *testing.T) {
+	g := NewGame(3, 5)
+	if g.Rows != 3 || g.Columns != 5 || len(g.Board) != 3 || len(g.Board[0]) != 5 {
+		t.Fatalf("NewGame(3, 5) produced a %dx%d game with a %dx%d board",
+			g.Rows, g.Columns, len(g.Board), len(g.Board[0]))
+	}
+
+	count := 0
+	for r := range g.Board {
+		for c := range g.Board[r] {
+			if g.Board[r][c] != 0 {
+				count++
+			}
+		}
+	}
+	if count != 2 {
+		t.Errorf("expected 2 starting tiles, got %d", count)
+	}
+}
+
+func TestSeedReproducesGame(t *testing.T) {
+	moves := []Direction{Left, Up, Right, Down, Left, Left, Up, Down, Right, Up}
+
+	play := func() *Game {
+		g := NewGame(DefaultGridN, DefaultGridN, WithSeed(42))
+		for _, dir := range moves {
+			if moved, _ := g.Move(dir); moved {
+				g.SpawnTile()
+			}
+		}
+		return g
+	}
+
+	a, b := play(), play()
+	if a.Seed != 42 {
+		t.Errorf("Seed = %d; want 42", a.Seed)
+	}
+	if !reflect.DeepEqual(a.Board, b.Board) || a.Score != b.Score {
+		t.Errorf("same seed and moves produced different games:\n%v (%d)\n%v (%d)",
+			a.Board, a.Score, b.Board, b.Score)
+	}
+}
+
+func TestWin(t *testing.T) {
+	g := NewGame(2, 2, WithSeed(1), WithTarget(8))
+	g.Board = [][]int{{4, 4}, {0, 0}}
+
+	if g.JustWon() {
+		t.Fatal("JustWon() = true before reaching the target")
+	}
+	g.Play(Left)
+	if !g.Won || !g.JustWon() {
+		t.Fatalf("reaching the target: Won = %v, JustWon() = %v", g.Won, g.JustWon())
+	}
+
+	g.KeepGoing()
+	if g.JustWon() {
+		t.Error("JustWon() = true after KeepGoing")
+	}
+
+	// Undoing and reaching the target again must not re-trigger the win
+	g.Undo()
+	g.Play(Left)
+	if !g.Won || g.JustWon() {
+		t.Errorf("after undo and replay: Won = %v, JustWon() = %v", g.Won, g.JustWon())
+	}
+}
+
+func TestMoveEvents(t *testing.T) {
+	cases := []struct {
+		name   string
+		dir    Direction
+		board  [][]int
+		events []Event
+		gain   int
+	}{
+		{
+			"no move",
+			Left,
+			[][]int{{2, 4}, {8, 0}},
+			nil,
+			0,
+		},
+		{
+			"slide right",
+			Right,
+			[][]int{{2, 0, 0}, {0, 0, 0}},
+			[]Event{{EventSlide, Cell{0, 0}, Cell{0, 2}, 2}},
+			0,
+		},
+		{
+			"merge left",
+			Left,
+			[][]int{{0, 2, 2}, {4, 0, 0}},
+			[]Event{
+				{EventMerge, Cell{0, 1}, Cell{0, 0}, 4},
+				{EventMerge, Cell{0, 2}, Cell{0, 0}, 4},
+			},
+			4,
+		},
+		{
+			"merge in place and slide up",
+			Up,
+			[][]int{{2, 0}, {2, 0}, {0, 8}},
+			[]Event{
+				{EventMerge, Cell{0, 0}, Cell{0, 0}, 4},
+				{EventMerge, Cell{1, 0}, Cell{0, 0}, 4},
+				{EventSlide, Cell{2, 1}, Cell{0, 1}, 8},
+			},
+			4,
+		},
+		{
+			"down",
+			Down,
+			[][]int{{4, 2}, {4, 0}, {0, 0}},
+			[]Event{
+				{EventMerge, Cell{1, 0}, Cell{2, 0}, 8},
+				{EventMerge, Cell{0, 0}, Cell{2, 0}, 8},
+				{EventSlide, Cell{0, 1}, Cell{2, 1}, 2},
+			},
+			8,
+		},
+	}
+
+	for _, c := range cases {
+		g := &Game{Board: c.board, Rows: len(c.board), Columns: len(c.board[0])}
+		events, gain := g.MoveEvents(c.dir)
+		if !reflect.DeepEqual(events, c.events) || gain != c.gain {
+			t.Errorf("%s: MoveEvents(%v) = %v, %d; want %v, %d",
+				c.name, c.dir, events, gain, c.events, c.gain)
+		}
+	}
+}
+
+func TestPlaySpawnEvent(t *testing.T) {
+	g := NewGame(2, 2, WithSeed(5))
+	g.Board = [][]int{{0, 2}, {0, 0}}
+
+	events, _ := g.Play(Left)
+	if len(events) != 2 {
+		t.Fatalf("Play(Left) returned %d events; want slide + spawn", len(events))
+	}
+
+	spawn := events[len(events)-1]
+	if spawn.Kind != EventSpawn {
+		t.Fatalf("last event kind = %v; want EventSpawn", spawn.Kind)
+	}
+	if g.Board[spawn.To.Row][spawn.To.Column] != spawn.Value || spawn.To == (Cell{0, 0}) {
+		t.Errorf("spawn event %+v doesn't match board %v", spawn, g.Board)
+	}
+}
+
+func TestFourChance(t *testing.T) {
+	for _, tt := range []struct {
+		chance float64
+		want   int
+	}{{0, 2}, {1, 4}} {
+		g := NewGame(4, 4, WithSeed(9), WithFourChance(tt.chance))
+		for range 20 {
+			for _, dir := range Directions {
+				events, _ := g.Play(dir)
+				if len(events) == 0 {
+					continue
+				}
+				if spawn := events[len(events)-1]; spawn.Value != tt.want {
+					t.Fatalf("chance %v spawned a %d; want %d", tt.chance, spawn.Value, tt.want)
+				}
+			}
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	g := NewGame(DefaultGridN, DefaultGridN, WithSeed(9))
+	g.Play(Left)
+	c := g.Clone()
+
+	if !reflect.DeepEqual(c.Board, g.Board) || c.Score != g.Score || c.UndoCount() != 0 {
+		t.Fatalf("Clone() = %v (%d, %d undos); want %v (%d)",
+			c.Board, c.Score, c.UndoCount(), g.Board, g.Score)
+	}
+
+	// Both copies spawn the same tiles but never share their board
+	for _, dir := range Directions {
+		g.Play(dir)
+		c.Play(dir)
+	}
+	if !reflect.DeepEqual(c.Board, g.Board) {
+		t.Errorf("clone diverged from the original:\n%v\n%v", c.Board, g.Board)
+	}
+	c.Board[0][0] = -1
+	if g.Board[0][0] == -1 {
+		t.Error("clone shares its board with the original")
+	}
+}
+
+func TestParseDirection(t *testing.T) {
+	for _, dir := range Directions {
+		got, err := ParseDirection(dir.String())
+		if err != nil || got != dir {
+			t.Errorf("ParseDirection(%q) = %v, %v; want %v", dir.String(), got, err, dir)
+		}
+	}
+	if _, err := ParseDirection("sideways"); err == nil {
+		t.Error("ParseDirection(\"sideways\") succeeded; want an error")
+	}
+}
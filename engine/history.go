@@ -0,0 +1,134 @@
+package engine
+
+// DefaultHistoryLimit is the number of turns that can be undone by default.
+const DefaultHistoryLimit = 100
+
+// snapshot captures everything needed to restore a game to an earlier turn.
+type snapshot struct {
+	board [][]int
+	score int
+	moves int
+	rng   []byte // marshalled random source state
+	next  int    // Game.Next
+}
+
+// history keeps bounded undo and redo stacks of snapshots.
+type history struct {
+	undo   []snapshot
+	redo   []snapshot
+	limit  int  // max number of undo entries kept, 0 disables undo
+	reroll bool // if true, undo does not rewind the random source
+}
+
+// WithHistoryLimit sets how many turns can be undone. 0 disables undo.
+func WithHistoryLimit(limit int) Option {
+	return func(g *Game) {
+		g.history.limit = max(limit, 0)
+	}
+}
+
+// WithUndoReroll lets an undone turn spawn a different tile when replayed.
+// By default undo also rewinds the random source, so repeating the same move
+// after an undo always spawns the same tile.
+func WithUndoReroll(allowed bool) Option {
+	return func(g *Game) {
+		g.history.reroll = allowed
+	}
+}
+
+// HistoryLimit returns how many turns can be undone at most.
+func (g *Game) HistoryLimit() int {
+	return g.history.limit
+}
+
+// UndoReroll reports whether undone turns may spawn a different tile.
+func (g *Game) UndoReroll() bool {
+	return g.history.reroll
+}
+
+// snapshot captures the current state of the game.
+func (g *Game) snapshot() snapshot {
+	board := NewBoard(g.Rows, g.Columns)
+	for row := range g.Rows {
+		copy(board[row], g.Board[row])
+	}
+
+	// NOTE: PCG.MarshalBinary never fails. A Game built as a struct literal
+	// has no source, and no state to keep.
+	var state []byte
+	if g.src != nil {
+		state, _ = g.src.MarshalBinary()
+	}
+
+	return snapshot{board: board, score: g.Score, moves: g.Moves, rng: state, next: g.Next}
+}
+
+// restore puts the game back into a previously captured state.
+func (g *Game) restore(s snapshot) {
+	for row := range g.Rows {
+		copy(g.Board[row], s.board[row])
+	}
+	g.Score = s.score
+	g.Moves = s.moves
+	g.Next = s.next // the preview the player saw, even with reroll
+
+	if !g.history.reroll && g.src != nil && s.rng != nil {
+		// NOTE: The state was produced by MarshalBinary, so this cannot fail.
+		_ = g.src.UnmarshalBinary(s.rng)
+	}
+}
+
+// record pushes a snapshot taken before a turn and forgets any redoable turns.
+func (g *Game) record(s snapshot) {
+	h := &g.history
+	h.redo = h.redo[:0]
+	if h.limit == 0 {
+		return
+	}
+
+	h.undo = append(h.undo, s)
+	if len(h.undo) > h.limit {
+		// Drop the oldest entry
+		h.undo = h.undo[len(h.undo)-h.limit:]
+	}
+}
+
+// Undo reverts the last turn (the move and the tile spawned after it).
+// Returns false if there is nothing to undo.
+func (g *Game) Undo() bool {
+	h := &g.history
+	if len(h.undo) == 0 {
+		return false
+	}
+
+	prev := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, g.snapshot())
+	g.restore(prev)
+	return true
+}
+
+// Redo re-applies the last undone turn.
+// Returns false if there is nothing to redo.
+func (g *Game) Redo() bool {
+	h := &g.history
+	if len(h.redo) == 0 {
+		return false
+	}
+
+	next := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, g.snapshot())
+	g.restore(next)
+	return true
+}
+
+// UndoCount returns how many turns can currently be undone.
+func (g *Game) UndoCount() int {
+	return len(g.history.undo)
+}
+
+// RedoCount returns how many undone turns can currently be redone.
+func (g *Game) RedoCount() int {
+	return len(g.history.redo)
+}
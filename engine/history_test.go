@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+// playUntilMoved plays directions in order until one of them changes the board.
+func playUntilMoved(t *testing.T, g *Game) {
+	t.Helper()
+	for _, dir := range []Direction{Left, Up, Right, Down} {
+		if events, _ := g.Play(dir); len(events) > 0 {
+			return
+		}
+	}
+	t.Fatal("no direction changed the board")
+}
+
+func TestUndoRedo(t *testing.T) {
+	g := NewGame(DefaultGridN, DefaultGridN, WithSeed(7))
+	start := g.snapshot()
+
+	playUntilMoved(t, g)
+	after := g.snapshot()
+
+	if !g.Undo() {
+		t.Fatal("Undo returned false after a move")
+	}
+	if !reflect.DeepEqual(g.snapshot(), start) {
+		t.Errorf("Undo did not restore the starting state")
+	}
+	if g.Undo() {
+		t.Error("Undo returned true with an empty history")
+	}
+
+	if !g.Redo() {
+		t.Fatal("Redo returned false after an undo")
+	}
+	if !reflect.DeepEqual(g.snapshot(), after) {
+		t.Errorf("Redo did not restore the state after the move")
+	}
+	if g.Redo() {
+		t.Error("Redo returned true with nothing to redo")
+	}
+}
+
+func TestUndoNoReroll(t *testing.T) {
+	g := NewGame(DefaultGridN, DefaultGridN, WithSeed(7))
+
+	playUntilMoved(t, g)
+	first := g.snapshot()
+
+	// Replaying the same turn after an undo must spawn the same tile
+	g.Undo()
+	playUntilMoved(t, g)
+	if !reflect.DeepEqual(g.snapshot(), first) {
+		t.Errorf("replaying an undone move produced a different board:\n%v\n%v",
+			g.Board, first.board)
+	}
+}
+
+func TestUndoReroll(t *testing.T) {
+	g := NewGame(DefaultGridN, DefaultGridN, WithSeed(7), WithUndoReroll(true))
+
+	playUntilMoved(t, g)
+	rngAfter := g.snapshot().rng
+
+	// With rerolls allowed, the random source keeps advancing through an undo
+	g.Undo()
+	if !reflect.DeepEqual(g.snapshot().rng, rngAfter) {
+		t.Error("Undo rewound the random source although rerolls are allowed")
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	g := NewGame(DefaultGridN, DefaultGridN, WithSeed(7), WithHistoryLimit(2))
+	for range 3 {
+		playUntilMoved(t, g)
+	}
+
+	if got := g.UndoCount(); got != 2 {
+		t.Errorf("UndoCount() = %d; want 2", got)
+	}
+
+	g.Undo()
+	g.Undo()
+	if g.Undo() {
+		t.Error("Undo went past the history limit")
+	}
+	if got := g.RedoCount(); got != 2 {
+		t.Errorf("RedoCount() = %d; want 2", got)
+	}
+
+	// A new move forgets the redoable turns
+	playUntilMoved(t, g)
+	if got := g.RedoCount(); got != 0 {
+		t.Errorf("RedoCount() after a new move = %d; want 0", got)
+	}
+}
+
+func TestSnapshotStructLiteral(t *testing.T) {
+	// A Game built as a struct literal has no random source
+	g := &Game{Board: [][]int{{2, 0}, {0, 4}}, Rows: 2, Columns: 2}
+	s := g.snapshot()
+	g.Board[0][0] = 8
+	g.restore(s)
+	if g.Board[0][0] != 2 {
+		t.Errorf("board after restore = %v; want the snapshot", g.Board)
+	}
+}
@@ -0,0 +1,80 @@
+package engine
+
+import "time"
+
+// EndReason tells why a game is over, see Game.Over.
+type EndReason int
+
+const (
+	EndStuck   EndReason = iota // no move is possible
+	EndTimeUp                   // the time limit was reached, see WithTimeLimit
+	EndNoMoves                  // the move budget is spent, see WithMoveLimit
+)
+
+func (r EndReason) String() string {
+	switch r {
+	case EndStuck:
+		return "stuck"
+	case EndTimeUp:
+		return "time-up"
+	case EndNoMoves:
+		return "no-moves"
+	}
+	return "invalid"
+}
+
+// WithTimeLimit ends the game once Elapsed reaches limit, for time-attack
+// games. 0, the default, is no limit.
+// NOTE: Elapsed is advanced by the frontend, which decides what counts as
+// playing time (e.g. not while the window is in the background).
+func WithTimeLimit(limit time.Duration) Option {
+	return func(g *Game) {
+		g.TimeLimit = max(limit, 0)
+	}
+}
+
+// WithMoveLimit ends the game after n turns, for move-budget games.
+// 0, the default, is no limit. Undoing a turn gives it back.
+func WithMoveLimit(n int) Option {
+	return func(g *Game) {
+		g.MoveLimit = max(n, 0)
+	}
+}
+
+// Over reports whether the game is over, and why. The limits are checked
+// before the board, so a spent budget wins over a stuck board.
+func (g *Game) Over() (EndReason, bool) {
+	if reason, ok := g.limitReached(); ok {
+		return reason, true
+	}
+	return EndStuck, !g.CanMove()
+}
+
+// limitReached reports whether the time limit or the move budget is used up.
+func (g *Game) limitReached() (EndReason, bool) {
+	switch {
+	case g.TimeLimit > 0 && g.Elapsed >= g.TimeLimit:
+		return EndTimeUp, true
+	case g.MoveLimit > 0 && g.Moves >= g.MoveLimit:
+		return EndNoMoves, true
+	}
+	return 0, false
+}
+
+// TimeLeft returns the playing time left before the time limit, 0 once it
+// has run out or without one.
+func (g *Game) TimeLeft() time.Duration {
+	if g.TimeLimit == 0 {
+		return 0
+	}
+	return max(g.TimeLimit-g.Elapsed, 0)
+}
+
+// MovesLeft returns the number of turns left in the move budget, 0 once
+// it is spent or without one.
+func (g *Game) MovesLeft() int {
+	if g.MoveLimit == 0 {
+		return 0
+	}
+	return max(g.MoveLimit-g.Moves, 0)
+}
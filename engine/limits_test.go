@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// playAny plays the first direction that changes the board.
+func playAny(g *Game) bool {
+	for _, dir := range Directions {
+		if events, _ := g.Play(dir); len(events) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTimeLimit(t *testing.T) {
+	g := NewGame(4, 4, WithSeed(1), WithTimeLimit(time.Minute))
+	if _, over := g.Over(); over || g.TimeLeft() != time.Minute {
+		t.Fatalf("new game: over %v, time left %v", over, g.TimeLeft())
+	}
+
+	g.Elapsed = 45 * time.Second
+	if !playAny(g) || g.TimeLeft() != 15*time.Second {
+		t.Fatalf("couldn't play with %v left", g.TimeLeft())
+	}
+
+	g.Elapsed = time.Minute
+	if reason, over := g.Over(); !over || reason != EndTimeUp {
+		t.Errorf("Over() = %v, %v; want time-up", reason, over)
+	}
+	if playAny(g) {
+		t.Error("played after the time ran out")
+	}
+	if g.TimeLeft() != 0 {
+		t.Errorf("TimeLeft() = %v; want 0", g.TimeLeft())
+	}
+}
+
+func TestMoveLimit(t *testing.T) {
+	g := NewGame(4, 4, WithSeed(1), WithMoveLimit(2))
+	for range 2 {
+		if !playAny(g) {
+			t.Fatal("couldn't play within the budget")
+		}
+	}
+	if reason, over := g.Over(); !over || reason != EndNoMoves || g.MovesLeft() != 0 {
+		t.Errorf("Over() = %v, %v with %d moves left; want no-moves", reason, over, g.MovesLeft())
+	}
+	if playAny(g) {
+		t.Error("played past the budget")
+	}
+
+	// Undo gives the turn back
+	g.Undo()
+	if _, over := g.Over(); over || g.MovesLeft() != 1 {
+		t.Errorf("after undo: over %v, %d moves left; want 1", over, g.MovesLeft())
+	}
+}
+
+func TestLimitsJSON(t *testing.T) {
+	g := NewGame(4, 4, WithTimeLimit(3*time.Minute), WithMoveLimit(200))
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Game
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.TimeLimit != 3*time.Minute || loaded.MoveLimit != 200 {
+		t.Errorf("loaded limits %v, %d; want 3m, 200", loaded.TimeLimit, loaded.MoveLimit)
+	}
+
+	// Games without limits, e.g. saved by older versions, never end on them
+	if reason, over := NewGame(4, 4).Over(); over {
+		t.Errorf("Over() = %v on a new game", reason)
+	}
+}
@@ -1,5 +1,7 @@
 package engine
 
+import "slices"
+
 // slideLIne shifts all non-zero tiles to the front
 // Returns the new line and true if any tile moved.
 // e.g.) [2, 0, 2, 4] -> [2, 2, 4, 0] (true)
@@ -31,16 +33,21 @@ func slideLine(line []int) ([]int, bool) {
 // But, it does not slide the tiles.
 // e.g.) [2, 2, 4, 0] -> [4, 0, 4, 0] (scoreGain: 4)
 func mergeLine(line []int) ([]int, int, bool) {
+	return mergeLineWith(Classic, line)
+}
+
+// mergeLineWith does the work of mergeLine, merging the tiles the rules allow.
+func mergeLineWith(r Rules, line []int) ([]int, int, bool) {
 	n := len(line)
 	scoreGain := 0
 	merged := false
 
 	for i := 0; i < n-1; i++ {
-		if line[i] != 0 && line[i] == line[i+1] {
+		if line[i] != 0 && line[i+1] != 0 && r.CanMerge(line[i], line[i+1]) {
 			// merge
-			line[i] *= 2
+			line[i] = r.Merge(line[i], line[i+1])
 			line[i+1] = 0
-			scoreGain += line[i]
+			scoreGain += r.Score(line[i])
 			merged = true
 
 			i++
@@ -51,21 +58,137 @@ func mergeLine(line []int) ([]int, int, bool) {
 	return line, scoreGain, merged
 }
 
+// lineMove records where one tile of a line ended up after slideMergeLine.
+type lineMove struct {
+	from, to int
+	merged   bool // the tile was combined with another one at `to`
+}
+
 // slideMergeLine combines sliding and merging in one step.
 // It first slides the line, then merges adjacent tiles,
 // and finally slides again to compact the line.
 // Returns the final line, a boolean indicating if any tile moved,
 func slideMergeLine(line []int) ([]int, bool, int) {
+	return slideMergeLineWith(Classic, line)
+}
+
+// slideMergeLineWith does the work of slideMergeLine with the given rules.
+func slideMergeLineWith(r Rules, line []int) ([]int, bool, int) {
+	final, moves, scoreGain := slideMergeLineMovesWith(r, line)
+
+	// The move is successful if any tile changed place or merged
+	moved := false
+	for _, m := range moves {
+		if m.from != m.to || m.merged {
+			moved = true
+		}
+	}
+	return final, moved, scoreGain
+}
+
+// slideMergeLineMoves does the work of slideMergeLine and also reports,
+// for every non-zero tile of the input, where it ended up.
+// e.g.) [2, 0, 2, 4] -> [4, 4, 0, 0], moves: 0->0 (merged), 2->0 (merged), 3->1
+func slideMergeLineMoves(line []int) ([]int, []lineMove, int) {
+	return slideMergeLineMovesWith(Classic, line)
+}
+
+// slideMergeLineMovesWith does the work of slideMergeLineMoves with the
+// given rules, moving the tiles one step only for SlideOneStep.
+func slideMergeLineMovesWith(r Rules, line []int) ([]int, []lineMove, int) {
+	if wall := slices.Index(line, Wall); wall >= 0 {
+		// NOTE: A wall splits the line into segments that move on their own;
+		// the wall itself never moves
+		front, moves, scoreGain := slideMergeLineMovesWith(r, line[:wall])
+		back, backMoves, backGain := slideMergeLineMovesWith(r, line[wall+1:])
+		for _, m := range backMoves {
+			moves = append(moves, lineMove{from: m.from + wall + 1, to: m.to + wall + 1, merged: m.merged})
+		}
+		final := append(append(front, Wall), back...)
+		return final, moves, scoreGain + backGain
+	}
+	if r.Movement().Slide == SlideOneStep {
+		return stepLineMoves(r, line)
+	}
+
+	// Remember where each tile comes from before sliding
+	var origins []int
+	for i, v := range line {
+		if v != 0 {
+			origins = append(origins, i)
+		}
+	}
+
 	// 1. initial slide
-	slid, moved1 := slideLine(line)
+	slid, _ := slideLine(line)
+	before := copyLine(slid) // mergeLine works in place
 
 	// 2. merge (Without the final slide inside it)
-	merged, scoreGain, didMerge := mergeLine(slid)
+	merged, scoreGain, _ := mergeLineWith(r, slid)
 
 	// 3. final slide to compact the line
 	final, _ := slideLine(merged)
 
-	// The move is successful if the initial slide did something or
-	// if a merge happened
-	return final, (moved1 || didMerge), scoreGain
+	// NOTE: After the first slide, tile k sits at index k. mergeLine either
+	// leaves it alone, turns it into the merge result (value changed), or
+	// absorbs it into its left neighbour (value zeroed). The final slide keeps
+	// the order of the survivors, so their rank among the non-zero cells is
+	// their final index.
+	moves := make([]lineMove, len(origins))
+	to := -1
+	for k, from := range origins {
+		switch {
+		case merged[k] == 0:
+			// Absorbed into the previous tile
+			moves[k] = lineMove{from: from, to: to, merged: true}
+		default:
+			to++
+			moves[k] = lineMove{from: from, to: to, merged: merged[k] != before[k]}
+		}
+	}
+	return final, moves, scoreGain
+}
+
+// stepLineMoves moves the tiles of a line one cell towards the front, the
+// way slideMergeLineMoves moves them as far as they can. The first tile
+// that can move, into a gap or a merge with the tile in front of it, moves
+// along with every tile behind it; the tiles in front stay.
+// e.g.) [3, 1, 2, 3] -> [3, 3, 3, 0], moves: 0->0, 1->1 (merged), 2->1 (merged), 3->2
+func stepLineMoves(r Rules, line []int) ([]int, []lineMove, int) {
+	n := len(line)
+	out := copyLine(line)
+	scoreGain := 0
+
+	first := n // index of the first tile that moves
+	for i := 1; i < n; i++ {
+		if line[i] != 0 && (line[i-1] == 0 || r.CanMerge(line[i-1], line[i])) {
+			first = i
+			break
+		}
+	}
+	if first < n {
+		if line[first-1] == 0 {
+			out[first-1] = line[first]
+		} else {
+			out[first-1] = r.Merge(line[first-1], line[first])
+			scoreGain += r.Score(out[first-1])
+		}
+		copy(out[first:], line[first+1:])
+		out[n-1] = 0
+	}
+
+	// NOTE: A tile in front of the first moving one only changes if that one
+	// merged into it, which only happens right in front of it
+	moves := []lineMove{}
+	for i, v := range line {
+		switch {
+		case v == 0:
+			continue
+		case i < first:
+			moves = append(moves, lineMove{from: i, to: i, merged: i == first-1 && first < n})
+		default:
+			moves = append(moves, lineMove{from: i, to: i - 1, merged: i == first && line[i-1] != 0})
+		}
+	}
+	return out, moves, scoreGain
 }
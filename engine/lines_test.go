@@ -112,3 +112,35 @@ func TestSlideMergeLine(t *testing.T) {
 		}
 	}
 }
+
+func TestSlideMergeLineMoves(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     []int
+		want      []int
+		moves     []lineMove
+		scoreGain int
+	}{
+		{"empty", []int{0, 0, 0, 0}, []int{0, 0, 0, 0}, []lineMove{}, 0},
+		{"slide only", []int{0, 2, 0, 4}, []int{2, 4, 0, 0},
+			[]lineMove{{1, 0, false}, {3, 1, false}}, 0},
+		{"no move", []int{2, 4, 8, 16}, []int{2, 4, 8, 16},
+			[]lineMove{{0, 0, false}, {1, 1, false}, {2, 2, false}, {3, 3, false}}, 0},
+		{"gap merge", []int{2, 0, 2, 4}, []int{4, 4, 0, 0},
+			[]lineMove{{0, 0, true}, {2, 0, true}, {3, 1, false}}, 4},
+		{"double merge", []int{2, 2, 2, 2}, []int{4, 4, 0, 0},
+			[]lineMove{{0, 0, true}, {1, 0, true}, {2, 1, true}, {3, 1, true}}, 8},
+		{"odd run", []int{0, 2, 2, 2}, []int{4, 2, 0, 0},
+			[]lineMove{{1, 0, true}, {2, 0, true}, {3, 1, false}}, 4},
+		{"no chain reaction", []int{4, 4, 8, 0}, []int{8, 8, 0, 0},
+			[]lineMove{{0, 0, true}, {1, 0, true}, {2, 1, false}}, 8},
+	}
+
+	for _, c := range cases {
+		got, moves, gain := slideMergeLineMoves(c.input)
+		if !reflect.DeepEqual(got, c.want) || !reflect.DeepEqual(moves, c.moves) || gain != c.scoreGain {
+			t.Errorf("%s: slideMergeLineMoves(%v) = %v, %v, %d; want %v, %v, %d",
+				c.name, c.input, got, moves, gain, c.want, c.moves, c.scoreGain)
+		}
+	}
+}
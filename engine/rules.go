@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"fmt"
+	"math/bits"
+	"math/rand/v2"
+)
+
+// Rules decide which tiles combine and into what, how merges score, which
+// tiles spawn and when a game is won. Each tile merges at most once per move;
+// how far tiles slide and where new ones appear is given by Movement.
+type Rules interface {
+	// Name identifies the rules in saves and replays, e.g. "classic".
+	Name() string
+
+	// CanMerge reports whether tile a can combine with its neighbour b,
+	// b being the tile behind a in the direction of the move.
+	// Neither is ever 0.
+	CanMerge(a, b int) bool
+
+	// Merge returns the tile made by combining a and b, see CanMerge.
+	// It must differ from a, as that is how merges are told from slides.
+	Merge(a, b int) int
+
+	// Score returns the points gained by making tile v in a merge.
+	Score(v int) int
+
+	// Spawn returns the value of a new tile. bigChance is the game's
+	// FourChance, the probability of spawning the larger of the usual tiles.
+	// NOTE: It must draw the same numbers from rng for a given bigChance,
+	// so that a seed reproduces the same game.
+	Spawn(rng *rand.Rand, bigChance float64) int
+
+	// SpawnOdds lists the values Spawn returns for bigChance along with
+	// their probabilities, e.g. for a solver weighing every spawn.
+	SpawnOdds(bigChance float64) []Odds
+
+	// Won reports whether the board holds the target, target being positive.
+	Won(board [][]int, target int) bool
+
+	// Target is the tile value that wins by default, see WithTarget.
+	Target() int
+
+	// Rank returns the position of a tile value in the sequence of values
+	// the rules make, 0 being the empty cell and 1 the smallest tile, e.g.
+	// for frontends to color tiles alike across rules.
+	Rank(v int) int
+
+	// Movement describes how tiles move and where new ones appear.
+	Movement() Movement
+}
+
+// Odds is a tile value and the probability that it spawns, see SpawnOdds.
+type Odds struct {
+	Value int
+	P     float64
+}
+
+// SlidePolicy says how far tiles move in one move, see Movement.
+type SlidePolicy int
+
+const (
+	SlideFull    SlidePolicy = iota // as far as they can, as in 2048
+	SlideOneStep                    // at most one cell, as in Threes
+)
+
+// SpawnPolicy says where new tiles appear after a move, see Movement.
+type SpawnPolicy int
+
+const (
+	SpawnAnywhere     SpawnPolicy = iota // on any empty cell
+	SpawnOppositeEdge                    // on an empty cell of the edge the move went away from
+)
+
+// Movement describes how the tiles of a rule set move and spawn.
+// The zero value is the movement of 2048.
+type Movement struct {
+	Slide   SlidePolicy
+	Spawn   SpawnPolicy
+	Preview bool // the next tile is drawn a turn ahead, see Game.Next
+}
+
+// Classic are the rules of the original 2048: equal tiles double and score
+// their new value, 2s and 4s spawn, and the game is won by making the target.
+var Classic Rules = classicRules{}
+
+// Variants lists every rule set a game can be played with, Classic first.
+var Variants = []Rules{Classic, Fibonacci, Threes}
+
+// FindRules returns the rule set of Variants with the given name.
+func FindRules(name string) (Rules, error) {
+	for _, r := range Variants {
+		if r.Name() == name {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("engine: unknown rules %q", name)
+}
+
+type classicRules struct{}
+
+func (classicRules) Name() string { return "classic" }
+
+func (classicRules) CanMerge(a, b int) bool { return a == b }
+
+func (classicRules) Merge(a, b int) int { return a + b }
+
+func (classicRules) Score(v int) int { return v }
+
+func (classicRules) Spawn(rng *rand.Rand, fourChance float64) int {
+	// - 90% chance of 2,
+	// - 10% chance of 4 (by default)
+	if rng.Float64() < fourChance {
+		return 4
+	}
+	return 2
+}
+
+func (classicRules) SpawnOdds(fourChance float64) []Odds {
+	return []Odds{{2, 1 - fourChance}, {4, fourChance}}
+}
+
+func (classicRules) Won(board [][]int, target int) bool {
+	return maxTile(board) >= target
+}
+
+func (classicRules) Target() int { return DefaultTarget }
+
+// Rank is the base-2 logarithm of v: 1 for a 2, 2 for a 4 and so on.
+func (classicRules) Rank(v int) int {
+	if v <= 0 {
+		return 0
+	}
+	return bits.Len(uint(v)) - 1
+}
+
+func (classicRules) Movement() Movement { return Movement{} }
+
+// maxTile returns the highest tile value on a board.
+func maxTile(board [][]int) int {
+	best := 0
+	for _, row := range board {
+		for _, v := range row {
+			best = max(best, v)
+		}
+	}
+	return best
+}
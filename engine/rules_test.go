@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+// tripleRules is a variant for the tests: only 3s spawn, and two equal
+// tiles merge into their triple, scoring 1 point per merge.
+type tripleRules struct{}
+
+func (tripleRules) Name() string                        { return "triple" }
+func (tripleRules) CanMerge(a, b int) bool              { return a == b }
+func (tripleRules) Merge(a, b int) int                  { return a * 3 }
+func (tripleRules) Score(v int) int                     { return 1 }
+func (tripleRules) Spawn(rng *rand.Rand, _ float64) int { return 3 }
+func (tripleRules) SpawnOdds(float64) []Odds            { return []Odds{{3, 1}} }
+func (tripleRules) Won(board [][]int, target int) bool  { return board[0][0] == target }
+func (tripleRules) Target() int                         { return 81 }
+func (tripleRules) Rank(v int) int                      { return v / 3 }
+func (tripleRules) Movement() Movement                  { return Movement{} }
+
+// leftOnlyRules merges a 1 behind a 2 but not the other way around.
+type leftOnlyRules struct{ classicRules }
+
+func (leftOnlyRules) CanMerge(a, b int) bool { return a == 2 && b == 1 }
+
+func TestClassicRules(t *testing.T) {
+	if r, err := FindRules("classic"); err != nil || r != Classic {
+		t.Fatalf("FindRules(classic) = %v, %v", r, err)
+	}
+	if _, err := FindRules("missing"); err == nil {
+		t.Error("FindRules(missing) succeeded")
+	}
+	if g := NewGame(4, 4); g.Rules != Classic {
+		t.Errorf("NewGame rules = %v; want Classic", g.Rules)
+	}
+}
+
+func TestSpawnOdds(t *testing.T) {
+	// Spawn draws each value about as often as SpawnOdds says
+	const n = 20000
+	for _, r := range Variants {
+		rng := rand.New(rand.NewPCG(1, 2))
+		counts := map[int]int{}
+		for range n {
+			counts[r.Spawn(rng, 0.1)]++
+		}
+		odds := r.SpawnOdds(0.1)
+		for _, o := range odds {
+			if got := float64(counts[o.Value]) / n; got < o.P-0.01 || got > o.P+0.01 {
+				t.Errorf("%s: %d spawned %.3f of the time; want %.3f", r.Name(), o.Value, got, o.P)
+			}
+			delete(counts, o.Value)
+		}
+		if len(counts) > 0 {
+			t.Errorf("%s: spawned %v, not in %v", r.Name(), counts, odds)
+		}
+	}
+
+	g := NewGame(4, 4, WithRules(Threes))
+	if got, want := g.SpawnOdds(), []Odds{{g.Next, 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("threes SpawnOdds() = %v; want %v", got, want)
+	}
+}
+
+func TestMoveWithRules(t *testing.T) {
+	g := NewGame(2, 3, WithSeed(1), WithRules(tripleRules{}), WithTarget(9))
+	g.Board = [][]int{{3, 3, 3}, {0, 0, 0}}
+	events, gain := g.Play(Left)
+	if gain != 1 || g.Board[0][0] != 9 || g.Board[0][1] != 3 {
+		t.Errorf("after left: board %v, gain %d; want [9 3 ...], 1", g.Board, gain)
+	}
+	if last := events[len(events)-1]; last.Kind != EventSpawn || last.Value != 3 {
+		t.Errorf("spawn event = %+v; want a 3", last)
+	}
+	if !g.Won {
+		t.Error("the rules' win condition was ignored")
+	}
+}
+
+func TestCanMoveWithRules(t *testing.T) {
+	// The merge is only allowed one way, which a move in the other direction reaches
+	g := &Game{Board: [][]int{{1, 2}, {4, 8}}, Rows: 2, Columns: 2, Rules: leftOnlyRules{}}
+	if !g.CanMove() {
+		t.Error("CanMove() = false; want true")
+	}
+	if moved, _ := g.Move(Right); !moved || !reflect.DeepEqual(g.Board, [][]int{{0, 3}, {4, 8}}) {
+		t.Errorf("right move: moved %v, board %v", moved, g.Board)
+	}
+
+	g = &Game{Board: [][]int{{1, 3}, {4, 8}}, Rows: 2, Columns: 2, Rules: leftOnlyRules{}}
+	if g.CanMove() {
+		t.Error("CanMove() = true on a board the rules can't merge")
+	}
+}
+
+func TestGameJSONRules(t *testing.T) {
+	var g Game
+	err := json.Unmarshal([]byte(`{"rows":2,"columns":2,"board":[[2,0],[0,4]],"rules":"chess"}`), &g)
+	if err == nil {
+		t.Error("loaded a game with unknown rules")
+	}
+
+	b, err := json.Marshal(NewGame(2, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &g); err != nil || g.Rules != Classic {
+		t.Errorf("loaded rules %v, %v; want Classic", g.Rules, err)
+	}
+}
@@ -1,40 +1,61 @@
 package engine
 
 import (
-	"math/rand"
+	"math/rand/v2"
 )
 
-// SpawnTile picks a random empty cell on the board and places a new tile (2 or 4)
+// SpawnTile picks a random empty cell on the board and places a new tile (2 or 4),
+// drawing every random decision from rng.
 // Returns true if a file was spawned, false if the board is full.
-func SpawnTile(board *[GridN][GridN]int) bool {
-	type coordinate struct {
-		row    int
-		column int
+func SpawnTile(board [][]int, rng *rand.Rand) bool {
+	_, _, ok := spawnTile(board, rng, emptyCells(board), func() int {
+		return Classic.Spawn(rng, DefaultFourChance)
+	})
+	return ok
+}
+
+// spawnTile does the work of SpawnTile on one of the given empty cells,
+// drawing the value with next once the cell is chosen, and also returns
+// where the tile was placed and its value.
+func spawnTile(board [][]int, rng *rand.Rand, empties []Cell, next func() int) (Cell, int, bool) {
+	if len(empties) == 0 {
+		// No empty cells, can't spawn a tile
+		return Cell{}, 0, false
 	}
-	var empties []coordinate
 
-	// Collect empty positions
+	// Choose a random empty cell
+	pos := empties[rng.IntN(len(empties))]
+	value := next()
+	board[pos.Row][pos.Column] = value
+
+	return pos, value, true
+}
+
+// emptyCells lists the empty cells of a board.
+func emptyCells(board [][]int) []Cell {
+	var empties []Cell
 	for row := range board {
 		for column := range board[row] {
 			if board[row][column] == 0 {
-				empties = append(empties, coordinate{row, column})
+				empties = append(empties, Cell{row, column})
 			}
 		}
 	}
-	if len(empties) == 0 {
-		// No empty cells, can't spawn a tile
-		return false
-	}
+	return empties
+}
 
-	// Choose a random empty cell
-	// - 90% chance of 2,
-	// - 10% chance of 4
-	pos := empties[rand.Intn(len(empties))]
-	value := 2
-	if rand.Float64() < 0.1 {
-		value = 4
+// edgeCells lists the empty cells of the edge a move in dir went away from,
+// e.g. the right column after a left move (see SpawnOppositeEdge).
+func edgeCells(board [][]int, dir Direction) []Cell {
+	var empties []Cell
+	for _, cell := range emptyCells(board) {
+		switch {
+		case dir == Left && cell.Column == len(board[0])-1,
+			dir == Right && cell.Column == 0,
+			dir == Up && cell.Row == len(board)-1,
+			dir == Down && cell.Row == 0:
+			empties = append(empties, cell)
+		}
 	}
-	board[pos.row][pos.column] = value
-
-	return true
+	return empties
 }
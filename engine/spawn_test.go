@@ -1,15 +1,22 @@
 package engine
 
 import (
+	"math/rand/v2"
+	"reflect"
 	"testing"
 )
 
+// testRNG returns a fixed-seed random source so spawn tests are reproducible.
+func testRNG() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
 // TestSpawnTileEmpty ensures that spawning on an empty board places exactly one tile (2 or 4).
 func TestSpawnTileEmpty(t *testing.T) {
 	// Initialize empty board
-	board := &[GridN][GridN]int{}
+	board := NewBoard(DefaultGridN, DefaultGridN)
 
-	ok := SpawnTile(board)
+	ok := SpawnTile(board, testRNG())
 	if !ok {
 		t.Fatal("SpawnTile returned false on an empty board, expected true")
 	}
@@ -34,7 +41,7 @@ func TestSpawnTileEmpty(t *testing.T) {
 // TestSpawnTileFull ensures that spawning on a full board returns false and doesn't modify the board.
 func TestSpawnTileFull(t *testing.T) {
 	// Initialize full board
-	board := &[GridN][GridN]int{}
+	board := NewBoard(DefaultGridN, DefaultGridN)
 	for r := range board {
 		for c := range board[r] {
 			board[r][c] = 2
@@ -42,13 +49,16 @@ func TestSpawnTileFull(t *testing.T) {
 	}
 
 	// Copy original for later comparison
-	op := *board
+	op := NewBoard(DefaultGridN, DefaultGridN)
+	for r := range board {
+		copy(op[r], board[r])
+	}
 
-	ok := SpawnTile(board)
+	ok := SpawnTile(board, testRNG())
 	if ok {
 		t.Fatal("SpawnTile returned true on a full board, expected false")
 	}
-	if *board != op {
+	if !reflect.DeepEqual(board, op) {
 		t.Error("board changed on full spawn attempt, expected no modifications")
 	}
 }
@@ -56,7 +66,7 @@ func TestSpawnTileFull(t *testing.T) {
 // TestSpawnTileSingleEmpty ensures that spawning when exactly one cell is empty fills that cell.
 func TestSpawnTileSingleEmpty(t *testing.T) {
 	// Initialize full board except one cell
-	board := &[GridN][GridN]int{}
+	board := NewBoard(DefaultGridN, DefaultGridN)
 	for r := range board {
 		for c := range board[r] {
 			board[r][c] = 2
@@ -66,7 +76,7 @@ func TestSpawnTileSingleEmpty(t *testing.T) {
 	emptyR, emptyC := 1, 2
 	board[emptyR][emptyC] = 0
 
-	ok := SpawnTile(board)
+	ok := SpawnTile(board, testRNG())
 	if !ok {
 		t.Fatal("SpawnTile returned false when one cell was empty, expected true")
 	}
@@ -74,3 +84,18 @@ func TestSpawnTileSingleEmpty(t *testing.T) {
 		t.Errorf("expected cell [%d][%d] to be filled, but it's still zero", emptyR, emptyC)
 	}
 }
+
+// TestSpawnTileDeterministic ensures that the same random source places the same tiles.
+func TestSpawnTileDeterministic(t *testing.T) {
+	a := NewBoard(DefaultGridN, DefaultGridN)
+	b := NewBoard(DefaultGridN, DefaultGridN)
+	rngA, rngB := testRNG(), testRNG()
+
+	for range DefaultGridN * DefaultGridN {
+		SpawnTile(a, rngA)
+		SpawnTile(b, rngB)
+		if !reflect.DeepEqual(a, b) {
+			t.Fatalf("boards diverged with identical sources: %v vs %v", a, b)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// gameJSON is the serialized form of a Game.
+// NOTE: Fields must only ever be added, never renamed or repurposed,
+// so that games saved by older versions keep loading.
+type gameJSON struct {
+	Rows      int           `json:"rows"`
+	Columns   int           `json:"columns"`
+	Board     [][]int       `json:"board"`
+	Score     int           `json:"score"`
+	Moves     int           `json:"moves"`
+	Seed      uint64        `json:"seed"`
+	Elapsed   time.Duration `json:"elapsed"`
+	Target    int           `json:"target"`
+	Won       bool          `json:"won"`
+	Continued bool          `json:"continued"`
+	Assisted  bool          `json:"assisted"`
+	RNG       []byte        `json:"rng"`
+	History   historyJSON   `json:"history"`
+
+	FourChance float64 `json:"four_chance"`
+	Rules      string  `json:"rules"` // Rules.Name
+	Next       int     `json:"next"`
+
+	TimeLimit time.Duration `json:"time_limit"`
+	MoveLimit int           `json:"move_limit"`
+}
+
+// historyJSON is the serialized form of the undo/redo stacks.
+type historyJSON struct {
+	Undo   []snapshotJSON `json:"undo"`
+	Redo   []snapshotJSON `json:"redo"`
+	Limit  int            `json:"limit"`
+	Reroll bool           `json:"reroll"`
+}
+
+// snapshotJSON is the serialized form of a snapshot.
+type snapshotJSON struct {
+	Board [][]int `json:"board"`
+	Score int     `json:"score"`
+	Moves int     `json:"moves"`
+	RNG   []byte  `json:"rng"`
+	Next  int     `json:"next"`
+}
+
+// MarshalJSON encodes the full game state, including the random source
+// and the undo/redo history, so a saved game resumes exactly where it stopped.
+func (g *Game) MarshalJSON() ([]byte, error) {
+	now := g.snapshot()
+	data := gameJSON{
+		Rows:       g.Rows,
+		Columns:    g.Columns,
+		Board:      now.board,
+		Score:      g.Score,
+		Moves:      g.Moves,
+		Seed:       g.Seed,
+		Elapsed:    g.Elapsed,
+		Target:     g.Target,
+		Won:        g.Won,
+		Continued:  g.Continued,
+		Assisted:   g.Assisted,
+		RNG:        now.rng,
+		FourChance: g.FourChance,
+		Rules:      g.rules().Name(),
+		Next:       g.Next,
+		TimeLimit:  g.TimeLimit,
+		MoveLimit:  g.MoveLimit,
+		History: historyJSON{
+			Undo:   encodeSnapshots(g.history.undo),
+			Redo:   encodeSnapshots(g.history.redo),
+			Limit:  g.history.limit,
+			Reroll: g.history.reroll,
+		},
+	}
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON restores a game encoded by MarshalJSON.
+// Fields missing from older encodings keep their NewGame defaults.
+func (g *Game) UnmarshalJSON(b []byte) error {
+	data := gameJSON{
+		Target:     DefaultTarget,
+		History:    historyJSON{Limit: DefaultHistoryLimit},
+		FourChance: DefaultFourChance,
+		Rules:      Classic.Name(),
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+
+	rules, err := FindRules(data.Rules)
+	if err != nil {
+		return err
+	}
+
+	if data.Rows < MinGridN || data.Columns < MinGridN {
+		return fmt.Errorf("engine: invalid board size %dx%d", data.Rows, data.Columns)
+	}
+	if err := checkBoard(data.Board, data.Rows, data.Columns); err != nil {
+		return err
+	}
+
+	undo, err := decodeSnapshots(data.History.Undo, data.Rows, data.Columns)
+	if err != nil {
+		return fmt.Errorf("engine: undo history: %w", err)
+	}
+	redo, err := decodeSnapshots(data.History.Redo, data.Rows, data.Columns)
+	if err != nil {
+		return fmt.Errorf("engine: redo history: %w", err)
+	}
+
+	*g = Game{
+		Board:      data.Board,
+		Rows:       data.Rows,
+		Columns:    data.Columns,
+		Score:      data.Score,
+		Moves:      data.Moves,
+		Seed:       data.Seed,
+		Elapsed:    data.Elapsed,
+		Target:     data.Target,
+		Won:        data.Won,
+		Continued:  data.Continued,
+		Assisted:   data.Assisted,
+		FourChance: data.FourChance,
+		Rules:      rules,
+		Next:       data.Next,
+		TimeLimit:  max(data.TimeLimit, 0),
+		MoveLimit:  max(data.MoveLimit, 0),
+		history: history{
+			undo:   undo,
+			redo:   redo,
+			limit:  max(data.History.Limit, 0),
+			reroll: data.History.Reroll,
+		},
+	}
+
+	g.src = newSource(g.Seed)
+	if data.RNG != nil {
+		if err := g.src.UnmarshalBinary(data.RNG); err != nil {
+			return fmt.Errorf("engine: random source: %w", err)
+		}
+	}
+	g.rng = rand.New(g.src)
+	return nil
+}
+
+// checkBoard verifies that a decoded board has the expected dimensions.
+func checkBoard(board [][]int, rows, columns int) error {
+	if len(board) != rows {
+		return fmt.Errorf("engine: board has %d rows, want %d", len(board), rows)
+	}
+	for row := range board {
+		if len(board[row]) != columns {
+			return fmt.Errorf("engine: board row %d has %d columns, want %d", row, len(board[row]), columns)
+		}
+	}
+	return nil
+}
+
+// encodeSnapshots converts history snapshots to their serialized form.
+func encodeSnapshots(snaps []snapshot) []snapshotJSON {
+	out := make([]snapshotJSON, len(snaps))
+	for i, s := range snaps {
+		out[i] = snapshotJSON{Board: s.board, Score: s.score, Moves: s.moves, RNG: s.rng, Next: s.next}
+	}
+	return out
+}
+
+// decodeSnapshots converts serialized snapshots back, validating each board.
+func decodeSnapshots(snaps []snapshotJSON, rows, columns int) ([]snapshot, error) {
+	out := make([]snapshot, len(snaps))
+	for i, s := range snaps {
+		if err := checkBoard(s.Board, rows, columns); err != nil {
+			return nil, err
+		}
+		out[i] = snapshot{board: s.Board, score: s.Score, moves: s.Moves, rng: s.RNG, next: s.Next}
+	}
+	return out, nil
+}
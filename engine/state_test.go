@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGameJSONRoundTrip(t *testing.T) {
+	g := NewGame(3, 5, WithSeed(11), WithHistoryLimit(5), WithFourChance(0.5))
+	for _, dir := range []Direction{Left, Up, Right, Down, Left} {
+		g.Play(dir)
+	}
+	g.Undo()
+	g.Assisted = true
+
+	b, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Game
+	if err := json.Unmarshal(b, &loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(loaded.snapshot(), g.snapshot()) {
+		t.Fatalf("loaded state differs:\n%+v\n%+v", loaded.snapshot(), g.snapshot())
+	}
+	if loaded.FourChance != g.FourChance || !loaded.Assisted {
+		t.Errorf("FourChance = %v, Assisted = %v; want %v, true", loaded.FourChance, loaded.Assisted, g.FourChance)
+	}
+	if loaded.UndoCount() != g.UndoCount() || loaded.RedoCount() != g.RedoCount() {
+		t.Errorf("history sizes = %d/%d; want %d/%d",
+			loaded.UndoCount(), loaded.RedoCount(), g.UndoCount(), g.RedoCount())
+	}
+
+	// Both games must continue identically, spawns included
+	for _, dir := range []Direction{Down, Right, Up, Left} {
+		g.Play(dir)
+		loaded.Play(dir)
+	}
+	if !reflect.DeepEqual(loaded.snapshot(), g.snapshot()) {
+		t.Errorf("games diverged after loading:\n%v\n%v", loaded.Board, g.Board)
+	}
+}
+
+func TestGameJSONMissingFields(t *testing.T) {
+	// An encoding without history or random state still loads with defaults
+	var g Game
+	err := json.Unmarshal([]byte(`{"rows":2,"columns":2,"board":[[2,0],[0,4]],"score":8,"seed":3}`), &g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Score != 8 || g.Seed != 3 || g.history.limit != DefaultHistoryLimit || g.FourChance != DefaultFourChance {
+		t.Errorf("got score %d, seed %d, limit %d, four chance %v", g.Score, g.Seed, g.history.limit, g.FourChance)
+	}
+	if !g.SpawnTile() {
+		t.Error("SpawnTile failed on a loaded game")
+	}
+}
+
+func TestGameJSONInvalid(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+	}{
+		{"too small", `{"rows":1,"columns":4,"board":[[0,0,0,0]]}`},
+		{"row count mismatch", `{"rows":2,"columns":2,"board":[[0,0]]}`},
+		{"column count mismatch", `{"rows":2,"columns":2,"board":[[0,0],[0]]}`},
+		{"bad history", `{"rows":2,"columns":2,"board":[[0,0],[0,0]],"history":{"undo":[{"board":[]}]}}`},
+	}
+
+	for _, c := range cases {
+		var g Game
+		if err := json.Unmarshal([]byte(c.data), &g); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"math/bits"
+	"math/rand/v2"
+)
+
+// Threes are the rules of the game 2048 derived from: tiles move one cell
+// per move, a 1 and a 2 make a 3, equal tiles from 3 up double, and new tiles
+// come in from the edge opposite the move, the next one being shown ahead.
+var Threes Rules = threesRules{}
+
+// ThreesTarget is the tile that wins a Threes game by default.
+const ThreesTarget = 768
+
+type threesRules struct{}
+
+func (threesRules) Name() string { return "threes" }
+
+func (threesRules) CanMerge(a, b int) bool {
+	return a+b == 3 || a == b && a >= 3
+}
+
+func (threesRules) Merge(a, b int) int { return a + b }
+
+func (threesRules) Score(v int) int { return v }
+
+// Spawn returns a 6 with probability bonusChance, otherwise a 1, a 2 or
+// a 3 with equal odds.
+func (threesRules) Spawn(rng *rand.Rand, bonusChance float64) int {
+	f := rng.Float64()
+	if f < bonusChance {
+		return 6
+	}
+	return 1 + min(int((f-bonusChance)/(1-bonusChance)*3), 2)
+}
+
+func (threesRules) SpawnOdds(bonusChance float64) []Odds {
+	p := (1 - bonusChance) / 3
+	return []Odds{{1, p}, {2, p}, {3, p}, {6, bonusChance}}
+}
+
+func (threesRules) Won(board [][]int, target int) bool {
+	return maxTile(board) >= target
+}
+
+func (threesRules) Target() int { return ThreesTarget }
+
+// Rank is 1 for a 1, 2 for a 2, 3 for a 3, 4 for a 6 and so on.
+func (threesRules) Rank(v int) int {
+	if v < 3 {
+		return max(v, 0)
+	}
+	return bits.Len(uint(v/3)) + 2
+}
+
+func (threesRules) Movement() Movement {
+	return Movement{Slide: SlideOneStep, Spawn: SpawnOppositeEdge, Preview: true}
+}
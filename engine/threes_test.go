@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestStepLineMoves(t *testing.T) {
+	cases := []struct {
+		name      string
+		input     []int
+		want      []int
+		moves     []lineMove
+		scoreGain int
+	}{
+		{"empty", []int{0, 0, 0, 0}, []int{0, 0, 0, 0}, []lineMove{}, 0},
+		{"one step", []int{0, 0, 1, 0}, []int{0, 1, 0, 0},
+			[]lineMove{{2, 1, false}}, 0},
+		{"tiles behind follow", []int{3, 0, 2, 6}, []int{3, 2, 6, 0},
+			[]lineMove{{0, 0, false}, {2, 1, false}, {3, 2, false}}, 0},
+		{"gap behind moves too", []int{0, 1, 0, 2}, []int{1, 0, 2, 0},
+			[]lineMove{{1, 0, false}, {3, 2, false}}, 0},
+		{"one and two", []int{3, 1, 2, 3}, []int{3, 3, 3, 0},
+			[]lineMove{{0, 0, false}, {1, 1, true}, {2, 1, true}, {3, 2, false}}, 3},
+		{"single merge", []int{3, 3, 3, 3}, []int{6, 3, 3, 0},
+			[]lineMove{{0, 0, true}, {1, 0, true}, {2, 1, false}, {3, 2, false}}, 6},
+		{"ones don't merge", []int{1, 1, 2, 2}, []int{1, 3, 2, 0},
+			[]lineMove{{0, 0, false}, {1, 1, true}, {2, 1, true}, {3, 2, false}}, 3},
+		{"no move", []int{1, 3, 6, 12}, []int{1, 3, 6, 12},
+			[]lineMove{{0, 0, false}, {1, 1, false}, {2, 2, false}, {3, 3, false}}, 0},
+	}
+
+	for _, c := range cases {
+		got, moves, gain := slideMergeLineMovesWith(Threes, c.input)
+		if !reflect.DeepEqual(got, c.want) || !reflect.DeepEqual(moves, c.moves) || gain != c.scoreGain {
+			t.Errorf("%s: stepLineMoves(%v) = %v, %v, %d; want %v, %v, %d",
+				c.name, c.input, got, moves, gain, c.want, c.moves, c.scoreGain)
+		}
+	}
+}
+
+func TestThreesSpawnOppositeEdge(t *testing.T) {
+	for _, dir := range Directions {
+		g := NewGame(4, 4, WithSeed(3), WithRules(Threes))
+		g.Board = [][]int{
+			{0, 0, 0, 0},
+			{0, 3, 3, 0},
+			{0, 3, 3, 0},
+			{0, 0, 0, 0},
+		}
+		next := g.Next
+		events, _ := g.Play(dir)
+		spawned := events[len(events)-1]
+		if spawned.Kind != EventSpawn || spawned.Value != next {
+			t.Fatalf("%v: last event = %+v; want a spawn of the previewed %d", dir, spawned, next)
+		}
+		if !slices.Contains(edgeCells(NewBoard(4, 4), dir), spawned.To) {
+			t.Errorf("%v: spawned at %+v, off the opposite edge", dir, spawned.To)
+		}
+		if g.Next < 1 || g.Next > 6 {
+			t.Errorf("%v: next tile = %d", dir, g.Next)
+		}
+	}
+}
+
+func TestThreesPreviewHistory(t *testing.T) {
+	g := NewGame(4, 4, WithSeed(8), WithRules(Threes))
+	if g.Next == 0 || g.Target != ThreesTarget {
+		t.Fatalf("new game: next %d, target %d", g.Next, g.Target)
+	}
+	if NewGame(4, 4, WithSeed(8)).Next != 0 {
+		t.Error("classic game has a next tile")
+	}
+
+	before := g.Next
+	for _, dir := range Directions {
+		if events, _ := g.Play(dir); len(events) > 0 {
+			break
+		}
+	}
+	g.Undo()
+	if g.Next != before {
+		t.Errorf("after undo, next = %d; want %d", g.Next, before)
+	}
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Game
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Next != g.Next || loaded.Rules != Threes {
+		t.Errorf("loaded next %d, rules %v; want %d, threes", loaded.Next, loaded.Rules, g.Next)
+	}
+}
+
+func TestThreesRank(t *testing.T) {
+	for v, want := range map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 6: 4, 12: 5, 768: 11} {
+		if got := Threes.Rank(v); got != want {
+			t.Errorf("Rank(%d) = %d; want %d", v, got, want)
+		}
+	}
+}
+
+func TestThreesSpawnOdds(t *testing.T) {
+	p := (1 - 0.1) / 3
+	want := []Odds{{1, p}, {2, p}, {3, p}, {6, 0.1}}
+	if got := (threesRules{}).SpawnOdds(0.1); !reflect.DeepEqual(got, want) {
+		t.Errorf("SpawnOdds(0.1) = %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// Wall is the value of a wall cell: it never moves, merges or holds a tile,
+// and splits its row and column into segments that move on their own.
+const Wall = -1
+
+// wallSalt derives the source of random walls from the seed of the game.
+const wallSalt = 0x6a09e667f3bcc908
+
+// WithWalls places walls on the given cells before the first tiles spawn,
+// e.g. from a puzzle layout. NewGame panics if a cell is off the board.
+func WithWalls(cells ...Cell) Option {
+	return func(g *Game) {
+		g.walls = append(g.walls, cells...)
+	}
+}
+
+// WithRandomWalls places n walls on random cells, after those of WithWalls,
+// always leaving room for the first two tiles and never boxing in a cell.
+// NOTE: The cells are drawn from their own source, derived from the seed, so
+// the spawns of a game are the same as with the resulting WithWalls layout,
+// which is how replays restore the walls (see WallCells).
+func WithRandomWalls(n int) Option {
+	return func(g *Game) {
+		g.randomWalls = max(n, 0)
+	}
+}
+
+// placeWalls puts the walls of WithWalls and WithRandomWalls on the board.
+func (g *Game) placeWalls() {
+	for _, cell := range g.walls {
+		if cell.Row < 0 || cell.Row >= g.Rows || cell.Column < 0 || cell.Column >= g.Columns {
+			panic(fmt.Sprintf("engine: wall %+v off the %dx%d board", cell, g.Rows, g.Columns))
+		}
+		g.Board[cell.Row][cell.Column] = Wall
+	}
+
+	rng := rand.New(newSource(g.Seed ^ wallSalt))
+	for range g.randomWalls {
+		empties := emptyCells(g.Board)
+		if len(empties) <= 2 {
+			break
+		}
+		// only walls that don't split the open cells into more regions
+		before := regions(g.Board)
+		var candidates []Cell
+		for _, cell := range empties {
+			g.Board[cell.Row][cell.Column] = Wall
+			if regions(g.Board) <= before {
+				candidates = append(candidates, cell)
+			}
+			g.Board[cell.Row][cell.Column] = 0
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		cell := candidates[rng.IntN(len(candidates))]
+		g.Board[cell.Row][cell.Column] = Wall
+	}
+	g.walls, g.randomWalls = nil, 0
+}
+
+// neighbours are the offsets of the cells next to a cell.
+var neighbours = [4]Cell{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+
+// hasTileNeighbour reports whether a tile sits next to the cell, so it can
+// slide into the cell if that is empty.
+func hasTileNeighbour(board [][]int, row, column int) bool {
+	for _, d := range neighbours {
+		r, c := row+d.Row, column+d.Column
+		if r >= 0 && r < len(board) && c >= 0 && c < len(board[r]) && board[r][c] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// regions counts the groups of open cells that walls separate.
+func regions(board [][]int) int {
+	seen := make([][]bool, len(board))
+	for row := range board {
+		seen[row] = make([]bool, len(board[row]))
+	}
+	count := 0
+	for row := range board {
+		for column := range board[row] {
+			if board[row][column] == Wall || seen[row][column] {
+				continue
+			}
+			count++
+			stack := []Cell{{row, column}}
+			seen[row][column] = true
+			for len(stack) > 0 {
+				cell := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				for _, d := range neighbours {
+					r, c := cell.Row+d.Row, cell.Column+d.Column
+					if r >= 0 && r < len(board) && c >= 0 && c < len(board[r]) &&
+						board[r][c] != Wall && !seen[r][c] {
+						seen[r][c] = true
+						stack = append(stack, Cell{r, c})
+					}
+				}
+			}
+		}
+	}
+	return count
+}
+
+// WallCells lists the wall cells of a board.
+func WallCells(board [][]int) []Cell {
+	var walls []Cell
+	for row := range board {
+		for column, v := range board[row] {
+			if v == Wall {
+				walls = append(walls, Cell{row, column})
+			}
+		}
+	}
+	return walls
+}
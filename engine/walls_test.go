@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSlideMergeLineWalls(t *testing.T) {
+	const W = Wall
+	cases := []struct {
+		name      string
+		input     []int
+		want      []int
+		moves     []lineMove
+		scoreGain int
+	}{
+		{"blocked", []int{0, W, 0, 2}, []int{0, W, 2, 0},
+			[]lineMove{{3, 2, false}}, 0},
+		{"no merge across", []int{2, W, 2, 0}, []int{2, W, 2, 0},
+			[]lineMove{{0, 0, false}, {2, 2, false}}, 0},
+		{"segments merge apart", []int{0, 2, 2, W, 4, 4}, []int{4, 0, 0, W, 8, 0},
+			[]lineMove{{1, 0, true}, {2, 0, true}, {4, 4, true}, {5, 4, true}}, 12},
+		{"two walls", []int{W, 2, W, 0, 2}, []int{W, 2, W, 2, 0},
+			[]lineMove{{1, 1, false}, {4, 3, false}}, 0},
+	}
+
+	for _, c := range cases {
+		got, moves, gain := slideMergeLineMovesWith(Classic, c.input)
+		if !reflect.DeepEqual(got, c.want) || !reflect.DeepEqual(moves, c.moves) || gain != c.scoreGain {
+			t.Errorf("%s: slideMergeLineMoves(%v) = %v, %v, %d; want %v, %v, %d",
+				c.name, c.input, got, moves, gain, c.want, c.moves, c.scoreGain)
+		}
+	}
+
+	// Threes moves each segment one step
+	got, _, _ := slideMergeLineMovesWith(Threes, []int{0, 1, W, 1, 2})
+	if want := []int{1, 0, W, 3, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("threes: got %v; want %v", got, want)
+	}
+}
+
+func TestCanMoveWalls(t *testing.T) {
+	// Equal walls don't merge, and tiles don't merge through them
+	g := &Game{Board: [][]int{{Wall, Wall}, {2, 4}}, Rows: 2, Columns: 2}
+	if g.CanMove() {
+		t.Error("CanMove() = true with only walls to merge")
+	}
+	g = &Game{Board: [][]int{{2, Wall, 2}, {4, 8, 4}}, Rows: 2, Columns: 3}
+	if g.CanMove() {
+		t.Error("CanMove() = true with a wall between two equal tiles")
+	}
+
+	// An empty cell boxed in by walls takes no tile
+	g = &Game{Board: [][]int{{0, Wall, 2}, {Wall, 4, 8}, {2, 8, 4}}, Rows: 3, Columns: 3}
+	if reason, over := g.Over(); !over || reason != EndStuck {
+		t.Errorf("Over() = %v, %v with a boxed-in empty cell; want %v, true", reason, over, EndStuck)
+	}
+}
+
+func TestWalls(t *testing.T) {
+	layout := []Cell{{0, 0}, {2, 3}}
+	g := NewGame(4, 4, WithSeed(9), WithWalls(layout...))
+	if got := WallCells(g.Board); !reflect.DeepEqual(got, layout) {
+		t.Fatalf("walls = %v; want %v", got, layout)
+	}
+	for range 50 {
+		for _, dir := range Directions {
+			g.Play(dir)
+		}
+	}
+	if got := WallCells(g.Board); !reflect.DeepEqual(got, layout) {
+		t.Errorf("after playing, walls = %v; want %v", got, layout)
+	}
+
+	// Random walls are reproducible, and play like the same layout
+	random := NewGame(4, 4, WithSeed(9), WithRandomWalls(3))
+	walls := WallCells(random.Board)
+	if len(walls) != 3 {
+		t.Fatalf("random walls = %v; want 3", walls)
+	}
+	same := NewGame(4, 4, WithSeed(9), WithWalls(walls...))
+	if !reflect.DeepEqual(random.Board, same.Board) {
+		t.Errorf("boards differ: %v and %v", random.Board, same.Board)
+	}
+
+	// Random walls never box in a cell
+	for seed := range uint64(50) {
+		g := NewGame(4, 4, WithSeed(seed), WithRandomWalls(8))
+		if n := regions(g.Board); n != 1 {
+			t.Errorf("seed %d: walls split the board into %d regions: %v", seed, n, g.Board)
+		}
+	}
+
+	// Room is always left for the first tiles
+	full := NewGame(2, 2, WithRandomWalls(10))
+	if len(WallCells(full.Board)) != 2 {
+		t.Errorf("board = %v; want 2 walls and 2 tiles", full.Board)
+	}
+}
@@ -12,6 +12,8 @@ import (
 func main() {
 	ebiten.SetWindowSize(engine.ScreenWidth, engine.ScreenHeight)
 	ebiten.SetWindowTitle("2048 Game")
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	ebiten.SetWindowClosingHandled(true) // the app saves the game before quitting
 	if err := ebiten.RunGame(ui.NewApp()); err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,241 @@
+package replay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"2048/engine"
+)
+
+// stepCodes maps step kinds and directions to their letter in the file.
+var (
+	moveCodes = map[engine.Direction]string{
+		engine.Left:  "l",
+		engine.Up:    "u",
+		engine.Right: "r",
+		engine.Down:  "d",
+	}
+	undoCode = "z"
+	redoCode = "y"
+)
+
+// WriteTo writes the replay in the text format described in the package doc.
+func (r *Replay) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "2048-replay %d\n", Version)
+	fmt.Fprintf(&b, "size %d %d\n", r.Rows, r.Columns)
+	fmt.Fprintf(&b, "seed %d\n", r.Seed)
+	fmt.Fprintf(&b, "target %d\n", r.Target)
+	fmt.Fprintf(&b, "undo %d %d\n", r.HistoryLimit, boolInt(r.UndoReroll))
+	fmt.Fprintf(&b, "spawn %s\n", strconv.FormatFloat(r.FourChance, 'g', -1, 64))
+	fmt.Fprintf(&b, "rules %s\n", r.Rules)
+	fmt.Fprintf(&b, "limits %d %d\n", r.TimeLimit.Milliseconds(), r.MoveLimit)
+
+	rows := make([]string, len(r.Initial))
+	for i, row := range r.Initial {
+		cells := make([]string, len(row))
+		for j, v := range row {
+			cells[j] = strconv.Itoa(v)
+		}
+		rows[i] = strings.Join(cells, " ")
+	}
+	fmt.Fprintf(&b, "board %s\n", strings.Join(rows, "/"))
+
+	for _, s := range r.Steps {
+		ms := s.At.Milliseconds()
+		switch s.Kind {
+		case StepMove:
+			fmt.Fprintf(&b, "%s %d %d %d %d\n", moveCodes[s.Dir], ms, s.Spawn.Row, s.Spawn.Column, s.Value)
+		case StepUndo:
+			fmt.Fprintf(&b, "%s %d\n", undoCode, ms)
+		case StepRedo:
+			fmt.Fprintf(&b, "%s %d\n", redoCode, ms)
+		}
+	}
+	if r.Finished {
+		fmt.Fprintf(&b, "end %d\n", r.Score)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Read parses a replay written by WriteTo.
+// It only checks the syntax; use Verify to check it against the engine.
+func Read(rd io.Reader) (*Replay, error) {
+	sc := bufio.NewScanner(rd)
+	r := &Replay{
+		Target:       engine.DefaultTarget,
+		HistoryLimit: engine.DefaultHistoryLimit,
+		FourChance:   engine.DefaultFourChance,
+		Rules:        engine.Classic.Name(),
+	}
+
+	lineNo := 0
+	header := false
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+
+		if !header {
+			if fields[0] != "2048-replay" || len(fields) != 2 {
+				return nil, fmt.Errorf("replay: line %d: not a replay file", lineNo)
+			}
+			header = true
+			continue
+		}
+
+		if err := r.parseLine(fields); err != nil {
+			return nil, fmt.Errorf("replay: line %d: %w", lineNo, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if !header {
+		return nil, fmt.Errorf("replay: empty file")
+	}
+	if r.Initial == nil {
+		return nil, fmt.Errorf("replay: missing initial board")
+	}
+	return r, nil
+}
+
+// parseLine interprets one non-empty line after the version header.
+func (r *Replay) parseLine(fields []string) error {
+	args := fields[1:]
+	ints, err := atoi(args)
+
+	switch key := fields[0]; key {
+	case "size":
+		if err != nil || len(ints) != 2 {
+			return fmt.Errorf("invalid size %q", args)
+		}
+		if r.Rows != 0 || r.Columns != 0 {
+			return fmt.Errorf("repeated size")
+		}
+		r.Rows, r.Columns = ints[0], ints[1]
+	case "seed":
+		if len(args) != 1 {
+			return fmt.Errorf("invalid seed %q", args)
+		}
+		seed, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid seed: %w", err)
+		}
+		r.Seed = seed
+	case "target":
+		if err != nil || len(ints) != 1 {
+			return fmt.Errorf("invalid target %q", args)
+		}
+		r.Target = ints[0]
+	case "undo":
+		if err != nil || len(ints) != 2 {
+			return fmt.Errorf("invalid undo settings %q", args)
+		}
+		r.HistoryLimit, r.UndoReroll = ints[0], ints[1] != 0
+	case "spawn":
+		if len(args) != 1 {
+			return fmt.Errorf("invalid spawn chance %q", args)
+		}
+		p, err := strconv.ParseFloat(args[0], 64)
+		if err != nil || p < 0 || p > 1 {
+			return fmt.Errorf("invalid spawn chance %q", args[0])
+		}
+		r.FourChance = p
+	case "rules":
+		if len(args) != 1 {
+			return fmt.Errorf("invalid rules %q", args)
+		}
+		r.Rules = args[0]
+	case "limits":
+		if err != nil || len(ints) != 2 || ints[0] < 0 || ints[1] < 0 {
+			return fmt.Errorf("invalid limits %q", args)
+		}
+		r.TimeLimit, r.MoveLimit = time.Duration(ints[0])*time.Millisecond, ints[1]
+	case "board":
+		board, err := parseBoard(strings.Join(args, " "), r.Rows, r.Columns)
+		if err != nil {
+			return err
+		}
+		r.Initial = board
+	case undoCode, redoCode:
+		if err != nil || len(ints) != 1 {
+			return fmt.Errorf("invalid %s step %q", key, args)
+		}
+		kind := StepUndo
+		if key == redoCode {
+			kind = StepRedo
+		}
+		r.Steps = append(r.Steps, Step{Kind: kind, At: time.Duration(ints[0]) * time.Millisecond})
+	case "end":
+		if err != nil || len(ints) != 1 {
+			return fmt.Errorf("invalid end %q", args)
+		}
+		r.Finish(ints[0])
+	default:
+		for dir, code := range moveCodes {
+			if key != code {
+				continue
+			}
+			if err != nil || len(ints) != 4 {
+				return fmt.Errorf("invalid %s step %q", key, args)
+			}
+			r.Steps = append(r.Steps, Step{
+				Kind:  StepMove,
+				Dir:   dir,
+				At:    time.Duration(ints[0]) * time.Millisecond,
+				Spawn: engine.Cell{Row: ints[1], Column: ints[2]},
+				Value: ints[3],
+			})
+			return nil
+		}
+		// Unknown keyword: written by a newer version, skip it
+	}
+	return nil
+}
+
+// parseBoard parses rows of space-separated values separated by '/'.
+func parseBoard(s string, rows, columns int) ([][]int, error) {
+	lines := strings.Split(s, "/")
+	if len(lines) != rows {
+		return nil, fmt.Errorf("board has %d rows, want %d", len(lines), rows)
+	}
+	board := make([][]int, rows)
+	for i, line := range lines {
+		row, err := atoi(strings.Fields(line))
+		if err != nil || len(row) != columns {
+			return nil, fmt.Errorf("invalid board row %q", line)
+		}
+		board[i] = row
+	}
+	return board, nil
+}
+
+// atoi converts every field to an int.
+func atoi(fields []string) ([]int, error) {
+	out := make([]int, len(fields))
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,214 @@
+// Package replay records how a game was played and plays it back.
+//
+// A replay is stored as a small line-oriented text file:
+//
+//	2048-replay 1
+//	size 4 4             rows, columns
+//	seed 1234
+//	target 2048
+//	undo 100 0           history limit, 1 if undone turns may reroll spawns
+//	spawn 0.1            probability that a spawned tile is a 4
+//	rules classic        name of the engine.Rules
+//	limits 180000 0      time limit in ms and move budget, 0 for none
+//	board 0 2 0 0/0 0 0 0/0 0 4 0/0 -1 0 0  initial board, -1 for walls
+//	l 1520 3 1 2         move: direction (l, u, r, d), time in ms, spawn row, column and value
+//	z 2100               undo, time in ms
+//	y 2400               redo, time in ms
+//	end 1234             final score
+//
+// Blank lines and lines starting with '#' are ignored, as are header lines
+// with an unknown keyword, so newer files stay readable by older versions.
+// Since the seed determines every spawn, a replay is checked by running its
+// moves through engine.Game again, see Replay.Frames.
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"2048/engine"
+)
+
+// Version is the format version written in the first line of a replay.
+const Version = 1
+
+// StepKind tells what the player did in a step.
+type StepKind int
+
+const (
+	StepMove StepKind = iota // played a turn
+	StepUndo                 // took back a turn
+	StepRedo                 // re-applied an undone turn
+)
+
+// Step is one action of the player.
+type Step struct {
+	Kind  StepKind
+	Dir   engine.Direction // StepMove only
+	Spawn engine.Cell      // where the move spawned a tile, StepMove only
+	Value int              // value of the spawned tile, StepMove only
+	At    time.Duration    // play time when the step happened
+}
+
+// Replay is the record of one game.
+type Replay struct {
+	Rows         int
+	Columns      int
+	Seed         uint64
+	Target       int
+	HistoryLimit int
+	UndoReroll   bool
+	FourChance   float64
+	Rules        string        // name of the engine.Rules
+	TimeLimit    time.Duration // see engine.WithTimeLimit
+	MoveLimit    int           // see engine.WithMoveLimit
+	Initial      [][]int       // board before the first step
+	Steps        []Step
+	Score        int  // final score, valid if Finished
+	Finished     bool // the game is over and Score was recorded
+}
+
+// New starts recording a game. It must be called right after engine.NewGame,
+// before any move, so that the replay can be verified from its seed.
+func New(g *engine.Game) *Replay {
+	initial := engine.NewBoard(g.Rows, g.Columns)
+	for r := range g.Board {
+		copy(initial[r], g.Board[r])
+	}
+	return &Replay{
+		Rows:         g.Rows,
+		Columns:      g.Columns,
+		Seed:         g.Seed,
+		Target:       g.Target,
+		HistoryLimit: g.HistoryLimit(),
+		UndoReroll:   g.UndoReroll(),
+		FourChance:   g.FourChance,
+		Rules:        g.Rules.Name(),
+		TimeLimit:    g.TimeLimit,
+		MoveLimit:    g.MoveLimit,
+		Initial:      initial,
+	}
+}
+
+// RecordMove adds a played turn, described by the events returned by
+// engine.Game.Play. Turns that didn't move anything are not recorded.
+// Recording a step after Finish reopens the replay, e.g. when the player
+// takes back the last move of a lost game.
+func (r *Replay) RecordMove(dir engine.Direction, events []engine.Event, at time.Duration) {
+	if len(events) == 0 {
+		return
+	}
+	r.Finished = false
+	step := Step{Kind: StepMove, Dir: dir, At: at}
+	if last := events[len(events)-1]; last.Kind == engine.EventSpawn {
+		step.Spawn, step.Value = last.To, last.Value
+	}
+	r.Steps = append(r.Steps, step)
+}
+
+// RecordUndo adds an undo.
+func (r *Replay) RecordUndo(at time.Duration) {
+	r.Steps = append(r.Steps, Step{Kind: StepUndo, At: at})
+	r.Finished = false
+}
+
+// RecordRedo adds a redo.
+func (r *Replay) RecordRedo(at time.Duration) {
+	r.Steps = append(r.Steps, Step{Kind: StepRedo, At: at})
+	r.Finished = false
+}
+
+// Finish marks the game as over with its final score.
+func (r *Replay) Finish(score int) {
+	r.Score, r.Finished = score, true
+}
+
+// Frame is the state of the game after a number of steps.
+type Frame struct {
+	Game   *engine.Game   // state after the step, must not be modified
+	Events []engine.Event // events of the step leading here, nil for undo/redo
+	At     time.Duration  // time of the step leading here
+	Undos  int            // turns that could be undone at this point
+}
+
+// ErrMismatch is wrapped by the errors of Frames when the replay doesn't
+// match what the engine does with the same seed and moves, i.e. it was
+// tampered with or recorded with different rules.
+var ErrMismatch = errors.New("replay: doesn't match the engine")
+
+// Frames re-runs the replay through the engine and returns the state after
+// every step; frame 0 is the initial board. It fails if any recorded spawn,
+// undo, redo or the final score doesn't match.
+func (r *Replay) Frames() ([]Frame, error) {
+	if r.Rows < engine.MinGridN || r.Columns < engine.MinGridN {
+		return nil, fmt.Errorf("replay: invalid board size %dx%d", r.Rows, r.Columns)
+	}
+	// NOTE: NewGame panics on walls off the board, so a board that doesn't
+	// match the size is rejected before they are taken from it.
+	if len(r.Initial) != r.Rows {
+		return nil, fmt.Errorf("replay: initial board has %d rows, want %d", len(r.Initial), r.Rows)
+	}
+	for i, row := range r.Initial {
+		if len(row) != r.Columns {
+			return nil, fmt.Errorf("replay: initial board row %d has %d cells, want %d", i, len(row), r.Columns)
+		}
+	}
+
+	rules, err := engine.FindRules(r.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+
+	g := engine.NewGame(r.Rows, r.Columns,
+		engine.WithSeed(r.Seed),
+		engine.WithTarget(r.Target),
+		engine.WithHistoryLimit(r.HistoryLimit),
+		engine.WithUndoReroll(r.UndoReroll),
+		engine.WithFourChance(r.FourChance),
+		engine.WithRules(rules),
+		engine.WithTimeLimit(r.TimeLimit),
+		engine.WithMoveLimit(r.MoveLimit),
+		engine.WithWalls(engine.WallCells(r.Initial)...))
+	if !reflect.DeepEqual(g.Board, r.Initial) {
+		return nil, fmt.Errorf("%w: initial board differs from seed %d", ErrMismatch, r.Seed)
+	}
+
+	frames := []Frame{{Game: g.Clone(), Undos: g.UndoCount()}}
+	for i, step := range r.Steps {
+		g.Elapsed = step.At // for the time limit
+		var events []engine.Event
+		switch step.Kind {
+		case StepMove:
+			events, _ = g.Play(step.Dir)
+			if len(events) == 0 {
+				return nil, fmt.Errorf("%w: step %d: moving %v changes nothing", ErrMismatch, i+1, step.Dir)
+			}
+			spawn := events[len(events)-1]
+			if spawn.Kind != engine.EventSpawn || spawn.To != step.Spawn || spawn.Value != step.Value {
+				return nil, fmt.Errorf("%w: step %d: spawn differs", ErrMismatch, i+1)
+			}
+		case StepUndo:
+			if !g.Undo() {
+				return nil, fmt.Errorf("%w: step %d: nothing to undo", ErrMismatch, i+1)
+			}
+		case StepRedo:
+			if !g.Redo() {
+				return nil, fmt.Errorf("%w: step %d: nothing to redo", ErrMismatch, i+1)
+			}
+		}
+		frames = append(frames, Frame{Game: g.Clone(), Events: events, At: step.At, Undos: g.UndoCount()})
+	}
+
+	if r.Finished && g.Score != r.Score {
+		return nil, fmt.Errorf("%w: final score is %d, not %d", ErrMismatch, g.Score, r.Score)
+	}
+	return frames, nil
+}
+
+// Verify checks that the replay matches the engine, see Frames.
+func (r *Replay) Verify() error {
+	_, err := r.Frames()
+	return err
+}
@@ -0,0 +1,185 @@
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"2048/engine"
+)
+
+// record plays a short game with an undo and a redo and returns its replay.
+func record(t *testing.T, opts ...engine.Option) (*Replay, *engine.Game) {
+	t.Helper()
+	g := engine.NewGame(engine.DefaultGridN, engine.DefaultGridN, append([]engine.Option{engine.WithSeed(42)}, opts...)...)
+	r := New(g)
+
+	at := time.Duration(0)
+	for i := 0; i < 40 && g.CanMove(); i++ {
+		at += 250 * time.Millisecond
+		dir := engine.Directions[i%len(engine.Directions)]
+		events, _ := g.Play(dir)
+		r.RecordMove(dir, events, at)
+
+		if i == 10 && g.Undo() {
+			r.RecordUndo(at)
+			if g.Redo() {
+				r.RecordRedo(at)
+			}
+		}
+	}
+	r.Finish(g.Score)
+	return r, g
+}
+
+func TestReplayVerify(t *testing.T) {
+	r, g := record(t)
+	frames, err := r.Frames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != len(r.Steps)+1 {
+		t.Fatalf("got %d frames for %d steps", len(frames), len(r.Steps))
+	}
+	last := frames[len(frames)-1].Game
+	if !reflect.DeepEqual(last.Board, g.Board) || last.Score != g.Score {
+		t.Errorf("replay ends on %v (score %d), want %v (score %d)", last.Board, last.Score, g.Board, g.Score)
+	}
+}
+
+func TestReplayRoundTrip(t *testing.T) {
+	r, _ := record(t)
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, r) {
+		t.Errorf("round trip changed the replay:\n got %+v\nwant %+v", got, r)
+	}
+	if err := got.Verify(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReplayTampered(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(r *Replay)
+	}{
+		{"spawn value", func(r *Replay) { r.Steps[0].Value = 8 }},
+		{"initial board", func(r *Replay) { r.Initial[0][0] = 1024 }},
+		{"seed", func(r *Replay) { r.Seed++ }},
+		{"spawn chance", func(r *Replay) { r.FourChance = 1 }},
+		{"final score", func(r *Replay) { r.Score += 100 }},
+		{"extra undo", func(r *Replay) {
+			r.Steps = append([]Step{{Kind: StepUndo}}, r.Steps...)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := record(t)
+			tt.tamper(r)
+			if err := r.Verify(); !errors.Is(err, ErrMismatch) {
+				t.Errorf("Verify() = %v, want ErrMismatch", err)
+			}
+		})
+	}
+}
+
+func TestReplayUnknownRules(t *testing.T) {
+	r, _ := record(t)
+	r.Rules = "chess"
+	if err := r.Verify(); err == nil || errors.Is(err, ErrMismatch) {
+		t.Errorf("Verify() = %v, want an unknown rules error", err)
+	}
+}
+
+func TestReplayWalls(t *testing.T) {
+	r, g := record(t, engine.WithRandomWalls(3))
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames, err := got.Frames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := frames[len(frames)-1].Game; !reflect.DeepEqual(last.Board, g.Board) {
+		t.Errorf("replay ends on %v, want %v", last.Board, g.Board)
+	}
+}
+
+func TestReplayLimits(t *testing.T) {
+	r, g := record(t, engine.WithMoveLimit(12), engine.WithTimeLimit(time.Minute))
+	if g.Moves != 12 {
+		t.Fatalf("played %d moves; want the budget of 12", g.Moves)
+	}
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TimeLimit != time.Minute || got.MoveLimit != 12 {
+		t.Errorf("limits = %v, %d; want 1m, 12", got.TimeLimit, got.MoveLimit)
+	}
+	if err := got.Verify(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"not a replay\n",
+		"2048-replay 1\nsize 4 4\n",              // no board
+		"2048-replay 1\nsize 2 2\nboard 0 2/0\n", // short row
+		"2048-replay 1\nsize 2 2\nboard 0 2/0 0\nl 10 0 0\n",  // missing spawn value
+		"2048-replay 1\nsize 2 2\nboard 0 2/0 0\nseed -1\n",   // negative seed
+		"2048-replay 1\nsize 2 2\nboard 0 2/0 0\nz soon\n",    // bad time
+		"2048-replay 1\nsize 2 2\nboard 0 2/0 0\nend lots\n",  // bad score
+		"2048-replay 1\nsize 2 2\nboard -1 2/0 0\nsize 4 4\n", // repeated size
+	}
+	for _, s := range tests {
+		if _, err := Read(strings.NewReader(s)); err == nil {
+			t.Errorf("Read(%q) succeeded, want an error", s)
+		}
+	}
+}
+
+func TestFramesBoardSize(t *testing.T) {
+	r := &Replay{Rows: 4, Columns: 4, Rules: engine.Classic.Name(),
+		Initial: [][]int{{-1, 2}, {0, 0}}}
+	if _, err := r.Frames(); err == nil {
+		t.Error("Frames() succeeded with a 2x2 board in a 4x4 replay, want an error")
+	}
+	r.Initial = [][]int{{0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 2, -1}}
+	if err := r.Verify(); err == nil {
+		t.Error("Verify() succeeded with a wall off the board, want an error")
+	}
+}
+
+func TestReadIgnoresUnknownLines(t *testing.T) {
+	s := "# comment\n2048-replay 2\nsize 2 2\ncolor blue\nboard 0 2/0 2\n\nl 5 0 1 2\n"
+	r, err := Read(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Step{{Kind: StepMove, Dir: engine.Left, At: 5 * time.Millisecond, Spawn: engine.Cell{Row: 0, Column: 1}, Value: 2}}
+	if !reflect.DeepEqual(r.Steps, want) {
+		t.Errorf("steps = %+v, want %+v", r.Steps, want)
+	}
+}
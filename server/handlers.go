@@ -0,0 +1,294 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"2048/engine"
+)
+
+// maxBodyBytes bounds the size of request bodies.
+const maxBodyBytes = 1 << 16
+
+// createRequest is the body of POST /games.
+type createRequest struct {
+	Rows    int      `json:"rows"`
+	Columns int      `json:"columns"`
+	Seed    *uint64  `json:"seed"`  // random if omitted
+	Walls   [][2]int `json:"walls"` // [row, column] of wall cells, e.g. from a puzzle layout
+}
+
+// moveRequest is the body of POST /games/{id}/moves.
+type moveRequest struct {
+	Direction string `json:"direction"`
+}
+
+// stateJSON describes a game.
+type stateJSON struct {
+	ID        string  `json:"id"`
+	Rows      int     `json:"rows"`
+	Columns   int     `json:"columns"`
+	Board     [][]int `json:"board"`
+	Score     int     `json:"score"`
+	Moves     int     `json:"moves"`
+	Seed      uint64  `json:"seed"`
+	MaxTile   int     `json:"max_tile"`
+	Target    int     `json:"target"`
+	Won       bool    `json:"won"`
+	Over      bool    `json:"over"`
+	UndoCount int     `json:"undo_count"`
+	EndReason string  `json:"end_reason,omitempty"` // engine.EndReason, once Over
+	Rules     string  `json:"rules"`                // engine.Rules.Name
+	Next      int     `json:"next,omitempty"`       // next tile, for rules that preview it
+}
+
+// eventJSON describes one tile transition.
+type eventJSON struct {
+	Kind  string `json:"kind"`
+	From  [2]int `json:"from"` // [row, column]
+	To    [2]int `json:"to"`
+	Value int    `json:"value"`
+}
+
+// turnJSON describes a played turn.
+type turnJSON struct {
+	Direction string      `json:"direction"`
+	Gain      int         `json:"gain"`
+	Score     int         `json:"score"` // after the turn
+	Events    []eventJSON `json:"events"`
+}
+
+// moveResponse is returned by POST /games/{id}/moves.
+type moveResponse struct {
+	Moved bool      `json:"moved"`
+	Turn  *turnJSON `json:"turn,omitempty"`
+	State stateJSON `json:"state"`
+}
+
+// historyResponse is returned by GET /games/{id}/history.
+type historyResponse struct {
+	Seed  uint64     `json:"seed"`
+	Turns []turnJSON `json:"turns"`
+}
+
+// errorResponse is the body of every error.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	req := createRequest{Rows: s.cfg.Game.Rows, Columns: s.cfg.Game.Columns}
+	if err := decodeBody(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Rows < engine.MinGridN || req.Columns < engine.MinGridN ||
+		req.Rows > s.cfg.MaxGridN || req.Columns > s.cfg.MaxGridN {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf(
+			"rows and columns must be between %d and %d", engine.MinGridN, s.cfg.MaxGridN))
+		return
+	}
+
+	walls, err := wallCells(req.Walls, req.Rows, req.Columns)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	opts := append(s.cfg.Game.GameOptions(), engine.WithWalls(walls...))
+	if req.Seed != nil {
+		opts = append(opts, engine.WithSeed(*req.Seed))
+	}
+	sess, err := s.add(engine.NewGame(req.Rows, req.Columns, opts...))
+	if errors.Is(err, errFull) {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "creating game failed")
+		return
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	w.Header().Set("Location", "/games/"+sess.id)
+	writeJSON(w, http.StatusCreated, sess.state())
+}
+
+// wallCells checks a wall layout for a board of the given size. It must
+// leave room for the first two tiles.
+func wallCells(walls [][2]int, rows, columns int) ([]engine.Cell, error) {
+	cells := make([]engine.Cell, 0, len(walls))
+	for _, w := range walls {
+		cell := engine.Cell{Row: w[0], Column: w[1]}
+		if cell.Row < 0 || cell.Row >= rows || cell.Column < 0 || cell.Column >= columns {
+			return nil, fmt.Errorf("wall %v is off the board", w)
+		}
+		if !slices.Contains(cells, cell) {
+			cells = append(cells, cell)
+		}
+	}
+	if len(cells) > rows*columns-2 {
+		return nil, errors.New("walls must leave at least 2 free cells")
+	}
+	return cells, nil
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	defer sess.mu.Unlock()
+	writeJSON(w, http.StatusOK, sess.state())
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if !s.remove(r.PathValue("id")) {
+		writeError(w, http.StatusNotFound, "no such game")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleMove(w http.ResponseWriter, r *http.Request) {
+	var req moveRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	dir, err := engine.ParseDirection(req.Direction)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sess, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	defer sess.mu.Unlock()
+
+	events, gain := sess.game.Play(dir)
+	resp := moveResponse{Moved: len(events) > 0}
+	if resp.Moved {
+		t := turnJSON{
+			Direction: dir.String(),
+			Gain:      gain,
+			Score:     sess.game.Score,
+			Events:    encodeEvents(events),
+		}
+		sess.turns = append(sess.turns, t)
+		resp.Turn = &t
+	}
+	resp.State = sess.state()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleUndo(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	defer sess.mu.Unlock()
+
+	if !sess.game.Undo() {
+		writeError(w, http.StatusConflict, "nothing to undo")
+		return
+	}
+	sess.turns = sess.turns[:len(sess.turns)-1]
+	writeJSON(w, http.StatusOK, sess.state())
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.session(w, r)
+	if !ok {
+		return
+	}
+	defer sess.mu.Unlock()
+
+	turns := sess.turns
+	if turns == nil {
+		turns = []turnJSON{}
+	}
+	writeJSON(w, http.StatusOK, historyResponse{Seed: sess.game.Seed, Turns: turns})
+}
+
+// session looks up the game named in the URL and locks it.
+// On failure it writes the error response and returns false.
+func (s *Server) session(w http.ResponseWriter, r *http.Request) (*session, bool) {
+	sess, ok := s.lookup(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "no such game")
+		return nil, false
+	}
+	sess.mu.Lock()
+	return sess, true
+}
+
+// state describes the session's game. The session must be locked.
+func (sess *session) state() stateJSON {
+	g := sess.game
+	reason, over := g.Over()
+	st := stateJSON{
+		ID:        sess.id,
+		Rows:      g.Rows,
+		Columns:   g.Columns,
+		Board:     g.Board,
+		Score:     g.Score,
+		Moves:     g.Moves,
+		Seed:      g.Seed,
+		MaxTile:   g.MaxTile(),
+		Target:    g.Target,
+		Won:       g.Won,
+		Over:      over,
+		UndoCount: g.UndoCount(),
+		Rules:     g.Rules.Name(),
+		Next:      g.Next,
+	}
+	if over {
+		st.EndReason = reason.String()
+	}
+	return st
+}
+
+// encodeEvents converts engine events to their JSON form.
+func encodeEvents(events []engine.Event) []eventJSON {
+	out := make([]eventJSON, len(events))
+	for i, e := range events {
+		out[i] = eventJSON{
+			Kind:  e.Kind.String(),
+			From:  [2]int{e.From.Row, e.From.Column},
+			To:    [2]int{e.To.Row, e.To.Column},
+			Value: e.Value,
+		}
+	}
+	return out
+}
+
+// decodeBody parses an optional JSON request body into v.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+// writeJSON sends v with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// NOTE: Nothing useful can be done if the client went away.
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError sends an error message with the given status code.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg})
+}
@@ -0,0 +1,188 @@
+// Package server hosts many concurrent games behind a JSON HTTP API,
+// for bots and web clients that don't link the windowed frontend.
+//
+// Routes:
+//
+//	POST   /games               create a game: {"rows", "columns", "seed", "walls"} (all optional)
+//	GET    /games/{id}          current state
+//	DELETE /games/{id}          end the session
+//	POST   /games/{id}/moves    play a turn: {"direction": "left"|"up"|"right"|"down"}
+//	POST   /games/{id}/undo     take back the last turn
+//	GET    /games/{id}/history  turns played so far
+//
+// The "walls" of a new game are [row, column] pairs, e.g. from a puzzle
+// layout; in the board of a state, wall cells hold -1 (engine.Wall).
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"2048/engine"
+	"2048/settings"
+)
+
+// Config controls the limits of a Server.
+type Config struct {
+	IdleTimeout time.Duration // sessions unused for this long are dropped
+	MaxSessions int           // concurrent sessions allowed
+	MaxGridN    int           // largest rows/columns a client may ask for
+
+	Game settings.Settings // board size and spawn odds when a request doesn't say, never timed
+}
+
+// DefaultConfig returns the limits used by cmd/2048-server.
+func DefaultConfig() Config {
+	return Config{
+		IdleTimeout: 30 * time.Minute,
+		MaxSessions: 10000,
+		MaxGridN:    settings.MaxGridN,
+		Game:        settings.Default(),
+	}
+}
+
+// Server is an http.Handler serving the game API.
+type Server struct {
+	cfg Config
+	mux *http.ServeMux
+	now func() time.Time // replaced in tests
+
+	mu       sync.Mutex // guards sessions
+	sessions map[string]*session
+}
+
+// session is one hosted game.
+// NOTE: engine.Game mutates in place and isn't safe for concurrent use,
+// so every access to game and turns goes through mu.
+type session struct {
+	mu       sync.Mutex
+	id       string
+	game     *engine.Game
+	turns    []turnJSON // history of the turns still on the board
+	lastUsed time.Time
+}
+
+// New creates a server with the given limits.
+func New(cfg Config) *Server {
+	// NOTE: Nothing advances the play time of hosted games, so a time attack
+	// would never end; the move budget needs no clock and is kept.
+	if cfg.Game.Challenge == settings.ChallengeTimed {
+		cfg.Game.Challenge = settings.ChallengeNone
+	}
+	s := &Server{
+		cfg:      cfg,
+		mux:      http.NewServeMux(),
+		now:      time.Now,
+		sessions: map[string]*session{},
+	}
+	s.mux.HandleFunc("POST /games", s.handleCreate)
+	s.mux.HandleFunc("GET /games/{id}", s.handleGet)
+	s.mux.HandleFunc("DELETE /games/{id}", s.handleDelete)
+	s.mux.HandleFunc("POST /games/{id}/moves", s.handleMove)
+	s.mux.HandleFunc("POST /games/{id}/undo", s.handleUndo)
+	s.mux.HandleFunc("GET /games/{id}/history", s.handleHistory)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// errFull is returned by add when MaxSessions games are in progress.
+var errFull = errors.New("too many games in progress")
+
+// add registers a new session. Returns errFull if the server is full.
+func (s *Server) add(g *engine.Game) (*session, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.sessions) >= s.cfg.MaxSessions {
+		return nil, errFull
+	}
+	sess := &session{id: id, game: g, lastUsed: s.now()}
+	s.sessions[sess.id] = sess
+	return sess, nil
+}
+
+// lookup returns a live session and marks it as used.
+// The caller must lock the session before touching its game.
+func (s *Server) lookup(id string) (*session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, false
+	}
+	now := s.now()
+	if now.Sub(sess.lastUsed) > s.cfg.IdleTimeout {
+		// Expired but not swept yet
+		delete(s.sessions, id)
+		return nil, false
+	}
+	sess.lastUsed = now
+	return sess, true
+}
+
+// remove drops a session. Returns false if it didn't exist.
+func (s *Server) remove(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.sessions[id]
+	delete(s.sessions, id)
+	return ok
+}
+
+// Sweep drops every session idle for longer than the configured timeout.
+// Returns the number of sessions removed.
+func (s *Server) Sweep() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	removed := 0
+	for id, sess := range s.sessions {
+		if now.Sub(sess.lastUsed) > s.cfg.IdleTimeout {
+			delete(s.sessions, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Expire calls Sweep periodically until ctx is done.
+func (s *Server) Expire(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sweep()
+		}
+	}
+}
+
+// newID returns a random, URL-safe session identifier.
+// NOTE: crypto/rand.Read only stopped returning errors in Go 1.24.
+func newID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("server: generating session id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,305 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"2048/engine"
+	"2048/settings"
+)
+
+// do sends a request to the server and decodes the JSON response into out (if not nil).
+func do(t *testing.T, h http.Handler, method, path, body string, out any) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if out != nil && rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+// create starts a game and returns its state.
+func create(t *testing.T, h http.Handler, body string) stateJSON {
+	t.Helper()
+	var st stateJSON
+	if code := do(t, h, "POST", "/games", body, &st); code != http.StatusCreated {
+		t.Fatalf("POST /games = %d; want 201", code)
+	}
+	return st
+}
+
+func TestCreateAndGet(t *testing.T) {
+	s := New(DefaultConfig())
+
+	st := create(t, s, `{"rows": 3, "columns": 5, "seed": 42}`)
+	if st.ID == "" || st.Rows != 3 || st.Columns != 5 || st.Seed != 42 || len(st.Board) != 3 {
+		t.Fatalf("created state = %+v", st)
+	}
+
+	var got stateJSON
+	if code := do(t, s, "GET", "/games/"+st.ID, "", &got); code != http.StatusOK {
+		t.Fatalf("GET = %d; want 200", code)
+	}
+	if got.ID != st.ID || got.Score != 0 || got.Over {
+		t.Errorf("GET state = %+v", got)
+	}
+
+	// Defaults apply without a body
+	def := create(t, s, "")
+	if def.Rows != 4 || def.Columns != 4 {
+		t.Errorf("default size = %dx%d; want 4x4", def.Rows, def.Columns)
+	}
+}
+
+func TestCreateSettings(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Game.Rows, cfg.Game.Columns = 5, 3
+	cfg.Game.FourChance = 1
+	s := New(cfg)
+
+	st := create(t, s, "")
+	if st.Rows != 5 || st.Columns != 3 {
+		t.Fatalf("default size = %dx%d; want the configured 3x5", st.Columns, st.Rows)
+	}
+	for _, row := range st.Board {
+		for _, v := range row {
+			if v != 0 && v != 4 {
+				t.Errorf("spawned a %d with only 4s configured", v)
+			}
+		}
+	}
+}
+
+func TestCreateWalls(t *testing.T) {
+	s := New(DefaultConfig())
+	st := create(t, s, `{"walls": [[0, 0], [3, 2], [0, 0]]}`)
+	walls := 0
+	for _, row := range st.Board {
+		for _, v := range row {
+			if v == engine.Wall {
+				walls++
+			}
+		}
+	}
+	if st.Board[0][0] != engine.Wall || st.Board[3][2] != engine.Wall || walls != 2 {
+		t.Errorf("board = %v; want walls at (0, 0) and (3, 2)", st.Board)
+	}
+}
+
+func TestMoveBudget(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Game.Challenge = settings.ChallengeMoves
+	s := New(cfg)
+	st := create(t, s, `{"seed": 3, "rows": 8, "columns": 8}`)
+	if st.Over || st.EndReason != "" {
+		t.Fatalf("new game state = %+v", st)
+	}
+	path := "/games/" + st.ID + "/moves"
+
+	// Play until the budget is spent
+	var resp moveResponse
+	for i := 0; i < 4*settings.MoveBudget && !resp.State.Over; i++ {
+		dir := []string{"left", "up", "right", "down"}[i%4]
+		do(t, s, "POST", path, `{"direction": "`+dir+`"}`, &resp)
+	}
+	if resp.State.Moves != settings.MoveBudget || resp.State.EndReason != engine.EndNoMoves.String() {
+		t.Errorf("final state after %d moves: over %v, reason %q", resp.State.Moves, resp.State.Over, resp.State.EndReason)
+	}
+}
+
+func TestTimedChallengeIgnored(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Game.Challenge = settings.ChallengeTimed
+	s := New(cfg)
+	st := create(t, s, `{"seed": 3}`)
+	if g := s.sessions[st.ID].game; g.TimeLimit != 0 {
+		t.Errorf("hosted game has a time limit of %v; want none", g.TimeLimit)
+	}
+}
+
+func TestCreateInvalid(t *testing.T) {
+	s := New(DefaultConfig())
+	for _, body := range []string{
+		`{"rows": 1, "columns": 4}`,
+		`{"rows": 4, "columns": 100}`,
+		`{"rows": "four"}`,
+		`{"unknown": true}`,
+		`{"walls": [[4, 0]]}`,
+		`{"rows": 2, "columns": 2, "walls": [[0, 0], [0, 1], [1, 0]]}`,
+		`{`,
+	} {
+		if code := do(t, s, "POST", "/games", body, nil); code != http.StatusBadRequest {
+			t.Errorf("POST /games %s = %d; want 400", body, code)
+		}
+	}
+}
+
+func TestMoveUndoHistory(t *testing.T) {
+	s := New(DefaultConfig())
+	st := create(t, s, `{"seed": 1}`)
+	path := "/games/" + st.ID
+
+	// Play until a move changes the board
+	var resp moveResponse
+	for _, dir := range []string{"left", "up", "right", "down"} {
+		if code := do(t, s, "POST", path+"/moves", `{"direction": "`+dir+`"}`, &resp); code != http.StatusOK {
+			t.Fatalf("move %s = %d; want 200", dir, code)
+		}
+		if resp.Moved {
+			break
+		}
+	}
+	if !resp.Moved || resp.Turn == nil || resp.State.Moves != 1 || resp.State.UndoCount != 1 {
+		t.Fatalf("move response = %+v", resp)
+	}
+	if last := resp.Turn.Events[len(resp.Turn.Events)-1]; last.Kind != "spawn" {
+		t.Errorf("last event = %+v; want a spawn", last)
+	}
+
+	var hist historyResponse
+	do(t, s, "GET", path+"/history", "", &hist)
+	if len(hist.Turns) != 1 || hist.Turns[0].Direction != resp.Turn.Direction || hist.Seed != 1 {
+		t.Errorf("history = %+v", hist)
+	}
+
+	var undone stateJSON
+	if code := do(t, s, "POST", path+"/undo", "", &undone); code != http.StatusOK {
+		t.Fatalf("undo = %d; want 200", code)
+	}
+	if undone.Moves != 0 {
+		t.Errorf("after undo, moves = %d; want 0", undone.Moves)
+	}
+	do(t, s, "GET", path+"/history", "", &hist)
+	if len(hist.Turns) != 0 {
+		t.Errorf("history after undo = %+v; want empty", hist.Turns)
+	}
+
+	if code := do(t, s, "POST", path+"/undo", "", nil); code != http.StatusConflict {
+		t.Errorf("undo with empty history = %d; want 409", code)
+	}
+	if code := do(t, s, "POST", path+"/moves", `{"direction": "sideways"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("invalid direction = %d; want 400", code)
+	}
+}
+
+func TestUnknownAndDeletedGame(t *testing.T) {
+	s := New(DefaultConfig())
+	if code := do(t, s, "GET", "/games/nope", "", nil); code != http.StatusNotFound {
+		t.Errorf("GET unknown = %d; want 404", code)
+	}
+
+	st := create(t, s, "")
+	if code := do(t, s, "DELETE", "/games/"+st.ID, "", nil); code != http.StatusNoContent {
+		t.Errorf("DELETE = %d; want 204", code)
+	}
+	if code := do(t, s, "POST", "/games/"+st.ID+"/moves", `{"direction": "left"}`, nil); code != http.StatusNotFound {
+		t.Errorf("move on deleted game = %d; want 404", code)
+	}
+}
+
+func TestIdleExpiry(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.IdleTimeout = time.Minute
+	s := New(cfg)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	idle := create(t, s, "")
+	active := create(t, s, "")
+
+	// Only the active game is used within the timeout
+	now = now.Add(45 * time.Second)
+	do(t, s, "GET", "/games/"+active.ID, "", nil)
+	now = now.Add(45 * time.Second)
+
+	if removed := s.Sweep(); removed != 1 {
+		t.Errorf("Sweep() removed %d sessions; want 1", removed)
+	}
+	if code := do(t, s, "GET", "/games/"+idle.ID, "", nil); code != http.StatusNotFound {
+		t.Errorf("GET idle game = %d; want 404", code)
+	}
+	if code := do(t, s, "GET", "/games/"+active.ID, "", nil); code != http.StatusOK {
+		t.Errorf("GET active game = %d; want 200", code)
+	}
+
+	// Expired sessions are refused even before a sweep
+	now = now.Add(2 * time.Minute)
+	if code := do(t, s, "GET", "/games/"+active.ID, "", nil); code != http.StatusNotFound {
+		t.Errorf("GET expired game = %d; want 404", code)
+	}
+}
+
+func TestMaxSessions(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxSessions = 1
+	s := New(cfg)
+
+	create(t, s, "")
+	if code := do(t, s, "POST", "/games", "", nil); code != http.StatusServiceUnavailable {
+		t.Errorf("POST beyond the limit = %d; want 503", code)
+	}
+}
+
+func TestConcurrentMoves(t *testing.T) {
+	srv := httptest.NewServer(New(DefaultConfig()))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/games", "application/json", bytes.NewBufferString(`{"seed": 5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var st stateJSON
+	json.NewDecoder(resp.Body).Decode(&st)
+	resp.Body.Close()
+
+	// Hammer the same game from several clients; the session lock keeps
+	// the move count and the history in step
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dirs := []string{"left", "up", "right", "down"}
+			for j := range 25 {
+				body := `{"direction": "` + dirs[(i+j)%4] + `"}`
+				resp, err := http.Post(srv.URL+"/games/"+st.ID+"/moves", "application/json", bytes.NewBufferString(body))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	resp, err = http.Get(srv.URL + "/games/" + st.ID + "/history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var hist historyResponse
+	json.NewDecoder(resp.Body).Decode(&hist)
+
+	resp2, err := http.Get(srv.URL + "/games/" + st.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp2.Body.Close()
+	json.NewDecoder(resp2.Body).Decode(&st)
+
+	if len(hist.Turns) != st.Moves {
+		t.Errorf("history has %d turns but the game played %d moves", len(hist.Turns), st.Moves)
+	}
+}
@@ -0,0 +1,33 @@
+package settings
+
+import (
+	"math/bits"
+	"strconv"
+)
+
+// compactUnits are the suffixes of the compact notation, in steps of 1000.
+var compactUnits = []string{"K", "M", "G", "T", "P", "E"}
+
+// FormatTile writes a tile value in one of Notations.
+// Values with 4 digits or less are always written in full, as are values
+// that aren't powers of two in the exponent notation.
+func FormatTile(v int, notation string) string {
+	if v < 10000 {
+		return strconv.Itoa(v)
+	}
+	switch notation {
+	case NumbersExponent:
+		if v&(v-1) == 0 {
+			return "2^" + strconv.Itoa(bits.Len(uint(v))-1)
+		}
+	case NumbersCompact:
+		// NOTE: Truncated rather than rounded, so 1023999 doesn't show as 1M
+		unit := -1
+		for v >= 1000 {
+			v /= 1000
+			unit++
+		}
+		return strconv.Itoa(v) + compactUnits[unit]
+	}
+	return strconv.Itoa(v)
+}
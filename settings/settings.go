@@ -0,0 +1,167 @@
+// Package settings holds the user's preferences. It doesn't depend on any
+// frontend, so the windowed game, the terminal game and the server all read
+// the same file (see storage.Store.LoadSettings).
+package settings
+
+import (
+	"slices"
+	"time"
+
+	"2048/engine"
+)
+
+// MaxGridN is the largest number of rows or columns that can be chosen.
+const MaxGridN = 16
+
+// Animation speeds, from none to slowest.
+const (
+	AnimationOff    = "off"
+	AnimationFast   = "fast"
+	AnimationNormal = "normal"
+	AnimationSlow   = "slow"
+)
+
+// AnimationSpeeds lists the valid values of Settings.Animation.
+var AnimationSpeeds = []string{AnimationOff, AnimationFast, AnimationNormal, AnimationSlow}
+
+// Notations of tile values, see FormatTile.
+const (
+	NumbersPlain    = "plain"    // 131072
+	NumbersExponent = "exponent" // 2^17
+	NumbersCompact  = "compact"  // 131K
+)
+
+// Notations lists the valid values of Settings.Numbers.
+var Notations = []string{NumbersPlain, NumbersExponent, NumbersCompact}
+
+// Challenges, which end games early and keep their own high scores.
+const (
+	ChallengeNone  = "none"  // play until no move is left
+	ChallengeTimed = "timed" // best score in TimeAttack
+	ChallengeMoves = "moves" // best score in MoveBudget moves
+)
+
+// Challenges lists the valid values of Settings.Challenge.
+var Challenges = []string{ChallengeNone, ChallengeTimed, ChallengeMoves}
+
+// Limits of the challenges.
+const (
+	TimeAttack = 3 * time.Minute
+	MoveBudget = 200
+)
+
+// UndoLimits lists the selectable values of Settings.UndoLimit.
+var UndoLimits = []int{0, 1, 10, engine.DefaultHistoryLimit, 1000}
+
+// MaxWalls is the largest number of random walls that can be chosen.
+const MaxWalls = 8
+
+// DefaultTheme is the name of the built-in theme used when none is chosen.
+const DefaultTheme = "classic"
+
+// Settings are the user's preferences.
+// NOTE: Fields must only ever be added, never renamed or repurposed, so that
+// older files keep loading; missing fields keep their Default values.
+type Settings struct {
+	Rows       int     `json:"rows"`        // board size of new games
+	Columns    int     `json:"columns"`     // board size of new games
+	Animation  string  `json:"animation"`   // one of AnimationSpeeds
+	Theme      string  `json:"theme"`       // name of the color theme
+	Volume     float64 `json:"volume"`      // sound volume, from 0 (muted) to 1
+	FourChance float64 `json:"four_chance"` // probability that a spawned tile is a 4
+	Numbers    string  `json:"numbers"`     // one of Notations
+	Fullscreen bool    `json:"fullscreen"`  // windowed game only
+	Rules      string  `json:"rules"`       // name of the engine.Variants of new games
+	Walls      int     `json:"walls"`       // number of random walls on new boards
+	Challenge  string  `json:"challenge"`   // one of Challenges
+	UndoLimit  int     `json:"undo_limit"`  // turns that can be undone, 0 disables undo
+	UndoReroll bool    `json:"undo_reroll"` // an undone turn may spawn another tile, for practice
+
+	// Input bindings, by action name. Actions missing from a map keep
+	// their built-in bindings; the names are chosen by the frontend.
+	Keys    map[string][]string `json:"keys,omitempty"`
+	Gamepad map[string][]string `json:"gamepad,omitempty"`
+}
+
+// Default returns the settings used until the user changes anything.
+func Default() Settings {
+	return Settings{
+		Rows:       engine.DefaultGridN,
+		Columns:    engine.DefaultGridN,
+		Animation:  AnimationNormal,
+		Theme:      DefaultTheme,
+		Volume:     0.8,
+		FourChance: engine.DefaultFourChance,
+		Numbers:    NumbersPlain,
+		Rules:      engine.Classic.Name(),
+		Challenge:  ChallengeNone,
+		UndoLimit:  engine.DefaultHistoryLimit,
+	}
+}
+
+// Normalize replaces the values that are out of range, e.g. after a manual
+// edit of the file, by their defaults.
+func (s *Settings) Normalize() {
+	def := Default()
+	if s.Rows < engine.MinGridN || s.Rows > MaxGridN || s.Columns < engine.MinGridN || s.Columns > MaxGridN {
+		s.Rows, s.Columns = def.Rows, def.Columns
+	}
+	if !slices.Contains(AnimationSpeeds, s.Animation) {
+		s.Animation = def.Animation
+	}
+	if s.Theme == "" {
+		s.Theme = def.Theme
+	}
+	if !(s.Volume >= 0 && s.Volume <= 1) { // also catches NaN
+		s.Volume = def.Volume
+	}
+	if !(s.FourChance >= 0 && s.FourChance <= 1) {
+		s.FourChance = def.FourChance
+	}
+	if !slices.Contains(Notations, s.Numbers) {
+		s.Numbers = def.Numbers
+	}
+	if _, err := engine.FindRules(s.Rules); err != nil {
+		s.Rules = def.Rules
+	}
+	if s.Walls < 0 || s.Walls > MaxWalls {
+		s.Walls = def.Walls
+	}
+	if !slices.Contains(Challenges, s.Challenge) {
+		s.Challenge = def.Challenge
+	}
+	if s.UndoLimit < 0 {
+		s.UndoLimit = def.UndoLimit
+	}
+}
+
+// GameOptions returns the engine options matching the settings.
+func (s Settings) GameOptions() []engine.Option {
+	opts := []engine.Option{
+		engine.WithFourChance(s.FourChance),
+		engine.WithRandomWalls(s.Walls),
+		engine.WithHistoryLimit(s.UndoLimit),
+		engine.WithUndoReroll(s.UndoReroll),
+	}
+	if r, err := engine.FindRules(s.Rules); err == nil {
+		opts = append(opts, engine.WithRules(r))
+	}
+	switch s.Challenge {
+	case ChallengeTimed:
+		opts = append(opts, engine.WithTimeLimit(TimeAttack))
+	case ChallengeMoves:
+		opts = append(opts, engine.WithMoveLimit(MoveBudget))
+	}
+	return opts
+}
+
+// Challenge returns the challenge a game is played in, see Challenges.
+func Challenge(g *engine.Game) string {
+	switch {
+	case g.TimeLimit > 0:
+		return ChallengeTimed
+	case g.MoveLimit > 0:
+		return ChallengeMoves
+	}
+	return ChallengeNone
+}
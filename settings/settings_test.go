@@ -0,0 +1,89 @@
+package settings
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"2048/engine"
+)
+
+func TestDefaultIsNormal(t *testing.T) {
+	s := Default()
+	s.Normalize()
+	if !reflect.DeepEqual(s, Default()) {
+		t.Errorf("Normalize changed the defaults: %+v", s)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(s *Settings)
+	}{
+		{"small board", func(s *Settings) { s.Rows = 1 }},
+		{"large board", func(s *Settings) { s.Columns = MaxGridN + 1 }},
+		{"animation", func(s *Settings) { s.Animation = "warp" }},
+		{"theme", func(s *Settings) { s.Theme = "" }},
+		{"volume", func(s *Settings) { s.Volume = 1.5 }},
+		{"NaN volume", func(s *Settings) { s.Volume = math.NaN() }},
+		{"four chance", func(s *Settings) { s.FourChance = -0.1 }},
+		{"numbers", func(s *Settings) { s.Numbers = "roman" }},
+		{"rules", func(s *Settings) { s.Rules = "chess" }},
+		{"walls", func(s *Settings) { s.Walls = MaxWalls + 1 }},
+		{"challenge", func(s *Settings) { s.Challenge = "marathon" }},
+		{"undo limit", func(s *Settings) { s.UndoLimit = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Default()
+			tt.edit(&s)
+			s.Normalize()
+			if !reflect.DeepEqual(s, Default()) {
+				t.Errorf("got %+v; want the defaults", s)
+			}
+		})
+	}
+}
+
+func TestNormalizeKeepsValidValues(t *testing.T) {
+	s := Settings{Rows: 3, Columns: 8, Animation: AnimationOff, Theme: "dark", Volume: 0, FourChance: 1, Numbers: NumbersCompact, Rules: "fibonacci", Walls: 2, Challenge: ChallengeTimed, UndoLimit: 0, UndoReroll: true}
+	want := s
+	s.Normalize()
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("got %+v; want %+v", s, want)
+	}
+	g := engine.NewGame(s.Rows, s.Columns, s.GameOptions()...)
+	if g.FourChance != 1 || g.Rules != engine.Fibonacci || len(engine.WallCells(g.Board)) != 2 {
+		t.Errorf("game FourChance = %v, rules %v, board %v; want 1, fibonacci, 2 walls", g.FourChance, g.Rules, g.Board)
+	}
+	if g.HistoryLimit() != 0 || !g.UndoReroll() {
+		t.Errorf("game history limit = %d, reroll %v; want 0, true", g.HistoryLimit(), g.UndoReroll())
+	}
+	if g.TimeLimit != TimeAttack || Challenge(g) != ChallengeTimed {
+		t.Errorf("game time limit = %v, challenge %q; want a timed game", g.TimeLimit, Challenge(g))
+	}
+}
+
+func TestFormatTile(t *testing.T) {
+	tests := []struct {
+		numbers string
+		v       int
+		want    string
+	}{
+		{NumbersPlain, 131072, "131072"},
+		{NumbersExponent, 2048, "2048"},
+		{NumbersExponent, 65536, "2^16"},
+		{NumbersExponent, 1 << 40, "2^40"},
+		{NumbersExponent, 10946, "10946"}, // not a power of two
+		{NumbersCompact, 8192, "8192"},
+		{NumbersCompact, 65536, "65K"},
+		{NumbersCompact, 1048576, "1M"},
+		{NumbersCompact, 1 << 31, "2G"},
+	}
+	for _, tt := range tests {
+		if got := FormatTile(tt.v, tt.numbers); got != tt.want {
+			t.Errorf("FormatTile(%d) in %s = %q; want %q", tt.v, tt.numbers, got, tt.want)
+		}
+	}
+}
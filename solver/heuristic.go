@@ -0,0 +1,123 @@
+package solver
+
+import "math/bits"
+
+// Evaluate scores a board for the search: higher is better.
+// Tiles are compared by their exponent (log2), so a 2048 next to a 1024
+// is as "smooth" as a 4 next to a 2.
+func Evaluate(board [][]int, w Weights) float64 {
+	rows, columns := len(board), len(board[0])
+	ranks := make([][]float64, rows)
+	for r := range board {
+		ranks[r] = make([]float64, columns)
+		for c, v := range board[r] {
+			ranks[r][c] = rank(v)
+		}
+	}
+
+	return w.Monotonicity*monotonicity(ranks) +
+		w.Smoothness*smoothness(ranks) +
+		w.Empty*float64(countEmpty(board)) +
+		w.CornerMax*cornerMax(ranks)
+}
+
+// rank returns log2 of a tile value, 0 for an empty cell.
+func rank(v int) float64 {
+	if v <= 0 {
+		return 0
+	}
+	return float64(bits.Len(uint(v)) - 1)
+}
+
+// monotonicity penalizes rows and columns that go up and down.
+// Each line is scored by the smaller of its total rises and total falls,
+// so a perfectly sorted line (in either direction) scores 0.
+func monotonicity(ranks [][]float64) float64 {
+	rows, columns := len(ranks), len(ranks[0])
+	penalty := 0.0
+
+	for r := range rows {
+		up, down := 0.0, 0.0
+		for c := 0; c < columns-1; c++ {
+			if d := ranks[r][c+1] - ranks[r][c]; d > 0 {
+				up += d
+			} else {
+				down -= d
+			}
+		}
+		penalty += min(up, down)
+	}
+
+	for c := range columns {
+		up, down := 0.0, 0.0
+		for r := 0; r < rows-1; r++ {
+			if d := ranks[r+1][c] - ranks[r][c]; d > 0 {
+				up += d
+			} else {
+				down -= d
+			}
+		}
+		penalty += min(up, down)
+	}
+	return -penalty
+}
+
+// smoothness penalizes differences between neighbouring non-empty tiles.
+func smoothness(ranks [][]float64) float64 {
+	rows, columns := len(ranks), len(ranks[0])
+	penalty := 0.0
+	for r := range rows {
+		for c := range columns {
+			if ranks[r][c] == 0 {
+				continue
+			}
+			if c+1 < columns && ranks[r][c+1] != 0 {
+				penalty += abs(ranks[r][c] - ranks[r][c+1])
+			}
+			if r+1 < rows && ranks[r+1][c] != 0 {
+				penalty += abs(ranks[r][c] - ranks[r+1][c])
+			}
+		}
+	}
+	return -penalty
+}
+
+// cornerMax rewards keeping the highest tile in a corner.
+func cornerMax(ranks [][]float64) float64 {
+	rows, columns := len(ranks), len(ranks[0])
+	highest := 0.0
+	for r := range ranks {
+		for _, v := range ranks[r] {
+			highest = max(highest, v)
+		}
+	}
+
+	for _, corner := range []float64{
+		ranks[0][0], ranks[0][columns-1], ranks[rows-1][0], ranks[rows-1][columns-1],
+	} {
+		if corner == highest {
+			return highest
+		}
+	}
+	return 0
+}
+
+// countEmpty returns the number of free cells on a board.
+func countEmpty(board [][]int) int {
+	n := 0
+	for r := range board {
+		for _, v := range board[r] {
+			if v == 0 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,118 @@
+// Package solver picks moves for an engine.Game with an expectimax search.
+package solver
+
+import (
+	"math"
+
+	"2048/engine"
+)
+
+// Weights scale the terms of the board evaluation heuristic.
+type Weights struct {
+	Monotonicity float64 // rows and columns that steadily increase or decrease
+	Smoothness   float64 // small differences between neighbouring tiles
+	Empty        float64 // number of free cells
+	CornerMax    float64 // highest tile sitting in a corner
+}
+
+// Config controls the search.
+type Config struct {
+	Depth   int // number of player moves to look ahead, at least 1
+	Weights Weights
+}
+
+// DefaultConfig returns a configuration that plays well while staying fast
+// enough to run every frame on the classic board.
+func DefaultConfig() Config {
+	return Config{
+		Depth: 2,
+		Weights: Weights{
+			Monotonicity: 1.0,
+			Smoothness:   0.1,
+			Empty:        2.7,
+			CornerMax:    1.0,
+		},
+	}
+}
+
+const (
+	// lossScore is the value of a board where no move is possible.
+	lossScore = -1e5
+
+	// maxChanceCells bounds how many empty cells a chance node expands.
+	// NOTE: Boards with many free cells are rarely critical, so sampling a
+	// few of them keeps large boards from blowing up the search.
+	maxChanceCells = 8
+)
+
+// Best returns the direction with the highest expected evaluation.
+// Returns false if no direction changes the board.
+func Best(g *engine.Game, cfg Config) (engine.Direction, bool) {
+	cfg.Depth = max(cfg.Depth, 1)
+
+	best, found := engine.Left, false
+	bestScore := math.Inf(-1)
+	for _, dir := range engine.Directions {
+		next := g.Clone()
+		if moved, _ := next.Move(dir); !moved {
+			continue
+		}
+		if score := chance(next, cfg, cfg.Depth-1, dir); score > bestScore {
+			best, bestScore, found = dir, score, true
+		}
+	}
+	return best, found
+}
+
+// player returns the value of the best move from g, looking depth moves ahead.
+func player(g *engine.Game, cfg Config, depth int) float64 {
+	best := math.Inf(-1)
+	for _, dir := range engine.Directions {
+		next := g.Clone()
+		if moved, _ := next.Move(dir); !moved {
+			continue
+		}
+		best = max(best, chance(next, cfg, depth-1, dir))
+	}
+	if math.IsInf(best, -1) {
+		return lossScore
+	}
+	return best
+}
+
+// chance returns the expected value of g over every possible spawn after
+// the board moved in dir, following the rules of g.
+func chance(g *engine.Game, cfg Config, depth int, dir engine.Direction) float64 {
+	if depth <= 0 {
+		return Evaluate(g.Board, cfg.Weights)
+	}
+
+	empties := g.SpawnCells(dir)
+	if len(empties) == 0 {
+		return player(g, cfg, depth)
+	}
+	if len(empties) > maxChanceCells {
+		// Spread the samples evenly over the board
+		step := float64(len(empties)) / maxChanceCells
+		sampled := make([]engine.Cell, maxChanceCells)
+		for i := range sampled {
+			sampled[i] = empties[int(float64(i)*step)]
+		}
+		empties = sampled
+	}
+
+	// NOTE: A previewed tile is known for this spawn only; the one after
+	// it is unknown to deeper levels, which weigh every value instead.
+	odds, next := g.SpawnOdds(), g.Next
+	g.Next = 0
+	total := 0.0
+	for _, cell := range empties {
+		for _, spawn := range odds {
+			g.Board[cell.Row][cell.Column] = spawn.Value
+			total += spawn.P * player(g, cfg, depth)
+		}
+		g.Board[cell.Row][cell.Column] = 0
+	}
+	g.Next = next
+	return total / float64(len(empties))
+}
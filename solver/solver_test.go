@@ -0,0 +1,121 @@
+package solver
+
+import (
+	"testing"
+
+	"2048/engine"
+)
+
+// newGame builds a game around a fixed board.
+func newGame(board [][]int) *engine.Game {
+	g := engine.NewGame(len(board), len(board[0]), engine.WithSeed(1))
+	g.Board = board
+	return g
+}
+
+func TestBestNoMove(t *testing.T) {
+	g := newGame([][]int{{2, 4}, {4, 2}})
+	if dir, ok := Best(g, DefaultConfig()); ok {
+		t.Errorf("Best() = %v, true on a stuck board; want false", dir)
+	}
+}
+
+func TestBestOnlyMove(t *testing.T) {
+	// Only a vertical move merges the 8s, and only Up keeps the 8 on the top row
+	g := newGame([][]int{
+		{8, 2, 4},
+		{8, 4, 2},
+		{2, 8, 4},
+	})
+	dir, ok := Best(g, DefaultConfig())
+	if !ok || (dir != engine.Up && dir != engine.Down) {
+		t.Errorf("Best() = %v, %v; want a vertical move", dir, ok)
+	}
+}
+
+func TestBestKeepsBoardIntact(t *testing.T) {
+	g := newGame([][]int{{2, 0, 2, 0}, {0, 4, 0, 0}, {0, 0, 0, 0}, {2, 0, 0, 8}})
+	before := g.Clone()
+	Best(g, DefaultConfig())
+	for r := range g.Board {
+		for c := range g.Board[r] {
+			if g.Board[r][c] != before.Board[r][c] {
+				t.Fatalf("Best() modified the board: %v; want %v", g.Board, before.Board)
+			}
+		}
+	}
+}
+
+func TestBestWithRules(t *testing.T) {
+	// The search spawns what the rules spawn, and leaves the preview alone
+	for _, rules := range engine.Variants {
+		g := engine.NewGame(4, 4, engine.WithSeed(5), engine.WithRules(rules))
+		for range 20 {
+			next := g.Next
+			dir, ok := Best(g, DefaultConfig())
+			if !ok {
+				break
+			}
+			if g.Next != next {
+				t.Fatalf("%s: Best() changed Next from %d to %d", rules.Name(), next, g.Next)
+			}
+			g.Play(dir)
+		}
+		if g.Moves == 0 {
+			t.Errorf("%s: solver played no move", rules.Name())
+		}
+	}
+}
+
+func TestEvaluatePrefersOrderedBoards(t *testing.T) {
+	w := DefaultConfig().Weights
+	ordered := [][]int{
+		{256, 128, 64, 32},
+		{16, 8, 4, 2},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+	}
+	scattered := [][]int{
+		{2, 128, 4, 32},
+		{16, 256, 8, 64},
+		{0, 0, 0, 0},
+		{0, 0, 0, 0},
+	}
+	if Evaluate(ordered, w) <= Evaluate(scattered, w) {
+		t.Errorf("Evaluate(ordered) = %v <= Evaluate(scattered) = %v",
+			Evaluate(ordered, w), Evaluate(scattered, w))
+	}
+}
+
+func TestSolverPlays(t *testing.T) {
+	if testing.Short() {
+		t.Skip("plays a full game")
+	}
+
+	g := engine.NewGame(engine.DefaultGridN, engine.DefaultGridN, engine.WithSeed(2048))
+	for g.CanMove() {
+		dir, ok := Best(g, DefaultConfig())
+		if !ok {
+			t.Fatal("Best() found no move although CanMove() is true")
+		}
+		g.Play(dir)
+	}
+
+	// A random player rarely gets past 128; the search should do far better
+	if g.MaxTile() < 1024 {
+		t.Errorf("solver reached only %d (score %d)", g.MaxTile(), g.Score)
+	}
+}
+
+func BenchmarkBest(b *testing.B) {
+	g := newGame([][]int{
+		{2, 4, 8, 16},
+		{0, 2, 0, 4},
+		{0, 0, 2, 0},
+		{0, 0, 0, 0},
+	})
+	cfg := DefaultConfig()
+	for range b.N {
+		Best(g, cfg)
+	}
+}
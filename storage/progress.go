@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"2048/engine"
+	"2048/replay"
+)
+
+// SaveProgress writes the game in progress and, if r isn't nil, the replay
+// recording it, replacing any previous save.
+func (s *Store) SaveProgress(g *engine.Game, r *replay.Replay) error {
+	if err := s.SaveGame(g); err != nil {
+		return fmt.Errorf("saving game: %w", err)
+	}
+	if r == nil {
+		return nil
+	}
+	if err := s.SaveCurrentReplay(r); err != nil {
+		return fmt.Errorf("saving replay: %w", err)
+	}
+	return nil
+}
+
+// LoadProgress reads the saved game and the replay recording it, which is
+// nil for saves made before replays were recorded.
+// NOTE: If only the replay can't be read, the game is still returned along
+// with the error, so it can be resumed without one.
+func (s *Store) LoadProgress() (*engine.Game, *replay.Replay, error) {
+	g, err := s.LoadGame()
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading game: %w", err)
+	}
+	r, err := s.LoadCurrentReplay()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return g, nil, nil
+		}
+		return g, nil, fmt.Errorf("loading replay: %w", err)
+	}
+	return g, r, nil
+}
+
+// DeleteProgress removes the saved game and its replay, if any.
+func (s *Store) DeleteProgress() error {
+	var errs []error
+	if err := s.DeleteGame(); err != nil {
+		errs = append(errs, fmt.Errorf("deleting saved game: %w", err))
+	}
+	if err := s.DeleteCurrentReplay(); err != nil {
+		errs = append(errs, fmt.Errorf("deleting replay: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// RecordGame adds a finished game to its high-score table in sc (see
+// Scores.Record) and finishes its replay r, if any. With a store s, the
+// scores and the replay are saved too; s is nil when the config dir is
+// unavailable.
+func RecordGame(s *Store, sc *Scores, g *engine.Game, r *replay.Replay, date time.Time) error {
+	ranked := Ranked(g)
+	if ranked {
+		sc.Record(g, date)
+	}
+	if r != nil {
+		r.Finish(g.Score)
+	}
+	if s == nil {
+		return nil
+	}
+
+	var errs []error
+	if ranked {
+		if err := s.SaveScores(sc); err != nil {
+			errs = append(errs, fmt.Errorf("saving high scores: %w", err))
+		}
+	}
+	if r != nil {
+		if err := s.ArchiveReplay(r, date); err != nil {
+			errs = append(errs, fmt.Errorf("saving replay: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"2048/engine"
+	"2048/replay"
+)
+
+func TestProgress(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	if _, _, err := s.LoadProgress(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("LoadProgress() error = %v; want os.ErrNotExist", err)
+	}
+
+	g := engine.NewGame(4, 4, engine.WithSeed(5))
+	if err := s.SaveProgress(g, nil); err != nil {
+		t.Fatal(err)
+	}
+	loaded, r, err := s.LoadProgress()
+	if err != nil || r != nil || !reflect.DeepEqual(loaded.Board, g.Board) {
+		t.Fatalf("LoadProgress() = %v, %v, %v; want the game without a replay", loaded, r, err)
+	}
+
+	r = replay.New(g)
+	events, _ := g.Play(engine.Left)
+	r.RecordMove(engine.Left, events, time.Second)
+	if err := s.SaveProgress(g, r); err != nil {
+		t.Fatal(err)
+	}
+	if _, loadedReplay, err := s.LoadProgress(); err != nil || !reflect.DeepEqual(loadedReplay, r) {
+		t.Errorf("LoadProgress() replay = %v, %v; want the saved one", loadedReplay, err)
+	}
+
+	if err := s.DeleteProgress(); err != nil {
+		t.Fatal(err)
+	}
+	if s.HasSavedGame() {
+		t.Error("HasSavedGame() = true after DeleteProgress")
+	}
+	if _, err := s.LoadCurrentReplay(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadCurrentReplay() after DeleteProgress: %v", err)
+	}
+}
+
+func TestRecordGame(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	sc := &Scores{}
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	g := engine.NewGame(4, 4, engine.WithSeed(5))
+	g.Play(engine.Left)
+	r := replay.New(g)
+	if err := RecordGame(s, sc, g, r, date); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Finished || r.Score != g.Score {
+		t.Errorf("replay finished = %v with %d; want true with %d", r.Finished, r.Score, g.Score)
+	}
+	if loaded, err := s.LoadScores(); err != nil || len(loaded.Entries) != 1 {
+		t.Errorf("LoadScores() = %+v, %v; want the game", loaded, err)
+	}
+	if names, err := s.ListReplays(); err != nil || len(names) != 1 {
+		t.Errorf("ListReplays() = %v, %v; want the replay", names, err)
+	}
+
+	// Games that aren't ranked only keep their replay, even without a store
+	practice := engine.NewGame(4, 4, engine.WithSeed(6), engine.WithRules(engine.Fibonacci))
+	if sc.TableFor(practice) != nil {
+		t.Error("TableFor() of a Fibonacci game isn't nil")
+	}
+	r = replay.New(practice)
+	if err := RecordGame(nil, sc, practice, r, date); err != nil {
+		t.Fatal(err)
+	}
+	if len(sc.Entries) != 1 || len(sc.Challenges) != 0 || !r.Finished {
+		t.Errorf("scores = %+v, replay finished = %v; want only the first game", sc, r.Finished)
+	}
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"2048/replay"
+)
+
+const (
+	currentReplayFile = "current.replay" // replay of the saved game
+	replaysDir        = "replays"        // replays of finished games
+	replayExt         = ".replay"
+
+	// MaxReplays is how many replays of finished games are kept.
+	MaxReplays = 20
+)
+
+// SaveCurrentReplay writes the replay of the game in progress, next to its save.
+func (s *Store) SaveCurrentReplay(r *replay.Replay) error {
+	return s.writeReplay(currentReplayFile, r)
+}
+
+// LoadCurrentReplay reads the replay of the saved game.
+// The error wraps os.ErrNotExist for saves made before replays were recorded.
+func (s *Store) LoadCurrentReplay() (*replay.Replay, error) {
+	return s.readReplay(currentReplayFile)
+}
+
+// DeleteCurrentReplay removes the replay of the saved game, if any.
+func (s *Store) DeleteCurrentReplay() error {
+	return s.remove(currentReplayFile)
+}
+
+// ArchiveReplay stores the replay of a finished game, dropping the oldest
+// ones beyond MaxReplays.
+func (s *Store) ArchiveReplay(r *replay.Replay, date time.Time) error {
+	// NOTE: The file names sort by date, which is all ListReplays relies on
+	name := filepath.Join(replaysDir, fmt.Sprintf("%s-%d%s", date.UTC().Format("20060102-150405"), r.Seed, replayExt))
+	if err := s.writeReplay(name, r); err != nil {
+		return err
+	}
+
+	names, err := s.ListReplays()
+	if err != nil {
+		return err
+	}
+	for _, old := range names[min(len(names), MaxReplays):] {
+		if err := s.remove(old); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListReplays returns the names of the archived replays, newest first.
+func (s *Store) ListReplays() ([]string, error) {
+	entries, err := os.ReadDir(s.path(replaysDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), replayExt) {
+			names = append(names, filepath.Join(replaysDir, e.Name()))
+		}
+	}
+	slices.Sort(names)
+	slices.Reverse(names)
+	return names, nil
+}
+
+// LoadReplay reads an archived replay by a name returned from ListReplays.
+func (s *Store) LoadReplay(name string) (*replay.Replay, error) {
+	return s.readReplay(name)
+}
+
+// readReplay parses the named replay file.
+func (s *Store) readReplay(name string) (*replay.Replay, error) {
+	b, err := os.ReadFile(s.path(name))
+	if err != nil {
+		return nil, err
+	}
+	r, err := replay.Read(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("storage: reading %s: %w", name, err)
+	}
+	return r, nil
+}
+
+// writeReplay writes the named replay file.
+func (s *Store) writeReplay(name string, r *replay.Replay) error {
+	var b bytes.Buffer
+	if _, err := r.WriteTo(&b); err != nil {
+		return err
+	}
+	return s.writeFile(name, b.Bytes())
+}
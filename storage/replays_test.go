@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"2048/engine"
+	"2048/replay"
+)
+
+func TestCurrentReplay(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	if _, err := s.LoadCurrentReplay(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("LoadCurrentReplay() error = %v; want os.ErrNotExist", err)
+	}
+
+	g := engine.NewGame(4, 4, engine.WithSeed(5))
+	r := replay.New(g)
+	events, _ := g.Play(engine.Left)
+	r.RecordMove(engine.Left, events, time.Second)
+
+	if err := s.SaveCurrentReplay(r); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := s.LoadCurrentReplay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, r) {
+		t.Errorf("loaded replay differs from the saved one")
+	}
+
+	if err := s.DeleteCurrentReplay(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LoadCurrentReplay(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadCurrentReplay() after delete: %v", err)
+	}
+}
+
+func TestArchiveReplayPrunes(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	if names, err := s.ListReplays(); err != nil || len(names) != 0 {
+		t.Fatalf("ListReplays() = %v, %v in an empty directory", names, err)
+	}
+
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range MaxReplays + 3 {
+		r := replay.New(engine.NewGame(3, 3, engine.WithSeed(uint64(i))))
+		if err := s.ArchiveReplay(r, date.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names, err := s.ListReplays()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != MaxReplays {
+		t.Fatalf("kept %d replays, want %d", len(names), MaxReplays)
+	}
+	newest, err := s.LoadReplay(names[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newest.Seed != MaxReplays+2 {
+		t.Errorf("newest replay has seed %d, want %d", newest.Seed, MaxReplays+2)
+	}
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"2048/engine"
+)
+
+const (
+	saveFile    = "savegame.json"
+	saveVersion = 1 // bump when the meaning of an existing field changes
+)
+
+// savedGame is the on-disk envelope around a serialized engine.Game.
+// NOTE: Unknown fields are ignored when decoding and missing ones keep their
+// defaults, so saves stay readable when either side gains new fields.
+type savedGame struct {
+	Version int          `json:"version"`
+	Game    *engine.Game `json:"game"`
+}
+
+// SaveGame writes the in-progress game, replacing any previous save.
+func (s *Store) SaveGame(g *engine.Game) error {
+	return s.writeJSON(saveFile, savedGame{Version: saveVersion, Game: g})
+}
+
+// LoadGame reads the saved game.
+// The error wraps os.ErrNotExist when there is no save.
+func (s *Store) LoadGame() (*engine.Game, error) {
+	var save savedGame
+	if err := s.readJSON(saveFile, &save); err != nil {
+		return nil, err
+	}
+	if save.Version < 1 || save.Game == nil {
+		return nil, fmt.Errorf("storage: %s is not a saved game", saveFile)
+	}
+	return save.Game, nil
+}
+
+// HasSavedGame reports whether a saved game exists.
+func (s *Store) HasSavedGame() bool {
+	_, err := os.Stat(s.path(saveFile))
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+// DeleteGame removes the saved game, if any.
+func (s *Store) DeleteGame() error {
+	return s.remove(saveFile)
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"2048/engine"
+)
+
+func TestSaveLoadGame(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	if s.HasSavedGame() {
+		t.Fatal("HasSavedGame() = true in an empty directory")
+	}
+	if _, err := s.LoadGame(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("LoadGame() error = %v; want os.ErrNotExist", err)
+	}
+
+	g := engine.NewGame(5, 5, engine.WithSeed(3))
+	g.Play(engine.Left)
+	g.Play(engine.Up)
+	if err := s.SaveGame(g); err != nil {
+		t.Fatal(err)
+	}
+	if !s.HasSavedGame() {
+		t.Fatal("HasSavedGame() = false after saving")
+	}
+
+	loaded, err := s.LoadGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Board, g.Board) || loaded.Score != g.Score ||
+		loaded.Moves != g.Moves || loaded.UndoCount() != g.UndoCount() {
+		t.Errorf("loaded game differs from the saved one")
+	}
+
+	if err := s.DeleteGame(); err != nil {
+		t.Fatal(err)
+	}
+	if s.HasSavedGame() {
+		t.Error("HasSavedGame() = true after deleting")
+	}
+	if err := s.DeleteGame(); err != nil {
+		t.Errorf("deleting a missing save: %v", err)
+	}
+}
+
+func TestLoadGameForwardCompatible(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+
+	// A save from a newer version with extra fields still loads
+	data := `{"version": 9, "future": true, "game": {"rows": 2, "columns": 2,
+		"board": [[2, 0], [0, 2]], "score": 4, "future": [1, 2, 3]}}`
+	if err := os.WriteFile(s.path(saveFile), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := s.LoadGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Score != 4 || g.Board[1][1] != 2 {
+		t.Errorf("loaded score %d, board %v", g.Score, g.Board)
+	}
+}
+
+func TestLoadGameCorrupt(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+
+	for _, data := range []string{`{not json`, `{"version": 0}`, `{"version": 1}`} {
+		if err := os.WriteFile(s.path(saveFile), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.LoadGame(); err == nil {
+			t.Errorf("LoadGame(%q) succeeded; want an error", data)
+		}
+	}
+}
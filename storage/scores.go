@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"2048/engine"
+	"2048/settings"
+)
+
+const (
+	scoresFile = "scores.json"
+	MaxScores  = 10 // number of entries kept in the high-score table
+)
+
+// ScoreEntry describes one finished game in the high-score table.
+type ScoreEntry struct {
+	Score    int           `json:"score"`
+	MaxTile  int           `json:"max_tile"`
+	Moves    int           `json:"moves"`
+	Duration time.Duration `json:"duration"`
+	Date     time.Time     `json:"date"`
+	Rows     int           `json:"rows"`
+	Columns  int           `json:"columns"`
+	Seed     uint64        `json:"seed"` // identifies the game, see Scores.Add
+	Target   int           `json:"target"`
+	Won      bool          `json:"won"` // the target tile was reached
+}
+
+// Table holds the best score ever reached and the top MaxScores games.
+type Table struct {
+	Best    int          `json:"best"`
+	Entries []ScoreEntry `json:"entries"` // sorted by descending score
+}
+
+// Scores holds the high-score table of regular games, and a table of its
+// own for each challenge (see settings.Challenges).
+// NOTE: The regular table is embedded, so files written before challenges
+// existed load into it unchanged.
+type Scores struct {
+	Table
+	Challenges map[string]*Table `json:"challenges,omitempty"` // by challenge name
+}
+
+// Challenge returns the table of a challenge, the regular one for
+// settings.ChallengeNone.
+func (sc *Scores) Challenge(name string) *Table {
+	if name == settings.ChallengeNone {
+		return &sc.Table
+	}
+	t, ok := sc.Challenges[name]
+	if !ok {
+		if sc.Challenges == nil {
+			sc.Challenges = make(map[string]*Table)
+		}
+		t = &Table{}
+		sc.Challenges[name] = t
+	}
+	return t
+}
+
+// Ranked reports whether a finished game may enter a high-score table.
+// Practice games, with custom spawn odds or undo rerolls, games the solver
+// helped with, games of other rules than Classic and games with walls don't
+// enter any.
+func Ranked(g *engine.Game) bool {
+	return g.FourChance == engine.DefaultFourChance && !g.UndoReroll() && !g.Assisted &&
+		g.Rules == engine.Classic && len(engine.WallCells(g.Board)) == 0
+}
+
+// TableFor returns the table a game competes in, that of its challenge
+// (see settings.Challenge), or nil if the game isn't Ranked.
+func (sc *Scores) TableFor(g *engine.Game) *Table {
+	if !Ranked(g) {
+		return nil
+	}
+	return sc.Challenge(settings.Challenge(g))
+}
+
+// Record adds a finished game to the table it competes in and returns its
+// rank there, or -1 if it didn't make the cut or isn't Ranked.
+func (sc *Scores) Record(g *engine.Game, date time.Time) int {
+	t := sc.TableFor(g)
+	if t == nil {
+		return -1
+	}
+	return t.Add(ScoreEntry{
+		Score:    g.Score,
+		MaxTile:  g.MaxTile(),
+		Moves:    g.Moves,
+		Duration: g.Elapsed,
+		Date:     date,
+		Rows:     g.Rows,
+		Columns:  g.Columns,
+		Seed:     g.Seed,
+		Target:   g.Target,
+		Won:      g.Won,
+	})
+}
+
+// Add records a finished game and returns its rank in the table (0 is the
+// top), or -1 if it didn't make the cut.
+// NOTE: A game that is undone after ending and finished again has the same
+// seed and size; only its better result is kept.
+func (t *Table) Add(e ScoreEntry) int {
+	t.Best = max(t.Best, e.Score)
+
+	if i := slices.IndexFunc(t.Entries, func(old ScoreEntry) bool {
+		return old.Seed == e.Seed && old.Rows == e.Rows && old.Columns == e.Columns
+	}); i >= 0 {
+		if t.Entries[i].Score >= e.Score {
+			return -1
+		}
+		t.Entries = slices.Delete(t.Entries, i, i+1)
+	}
+
+	// Insert after entries with an equal or better score
+	rank, _ := slices.BinarySearchFunc(t.Entries, e.Score, func(old ScoreEntry, score int) int {
+		if old.Score >= score {
+			return -1
+		}
+		return 1
+	})
+	if rank >= MaxScores {
+		return -1
+	}
+	t.Entries = slices.Insert(t.Entries, rank, e)
+	if len(t.Entries) > MaxScores {
+		t.Entries = t.Entries[:MaxScores]
+	}
+	return rank
+}
+
+// LoadScores reads the high-score table. A missing file yields an empty table.
+// A corrupt file is moved aside (to scores.json.corrupt) and also yields an
+// empty table, along with an error describing the problem.
+func (s *Store) LoadScores() (*Scores, error) {
+	sc := &Scores{}
+	err := s.readJSON(scoresFile, sc)
+	switch {
+	case err == nil:
+		sc.normalize()
+		return sc, nil
+	case errors.Is(err, os.ErrNotExist):
+		return &Scores{}, nil
+	}
+
+	// Don't let the next save overwrite whatever is left of the old table
+	if renameErr := os.Rename(s.path(scoresFile), s.path(scoresFile+".corrupt")); renameErr != nil {
+		err = errors.Join(err, renameErr)
+	}
+	return &Scores{}, fmt.Errorf("storage: high scores reset: %w", err)
+}
+
+// SaveScores writes the high-score table.
+func (s *Store) SaveScores(sc *Scores) error {
+	return s.writeJSON(scoresFile, sc)
+}
+
+// normalize repairs hand-edited tables, see Table.normalize.
+func (sc *Scores) normalize() {
+	sc.Table.normalize()
+	for name, t := range sc.Challenges {
+		if t == nil {
+			delete(sc.Challenges, name)
+			continue
+		}
+		t.normalize()
+	}
+}
+
+// normalize repairs a hand-edited table: it sorts and trims the entries and
+// makes sure Best is not lower than any of them.
+func (t *Table) normalize() {
+	slices.SortStableFunc(t.Entries, func(a, b ScoreEntry) int {
+		return b.Score - a.Score
+	})
+	if len(t.Entries) > MaxScores {
+		t.Entries = t.Entries[:MaxScores]
+	}
+	if len(t.Entries) > 0 {
+		t.Best = max(t.Best, t.Entries[0].Score)
+	}
+}
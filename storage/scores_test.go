@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"2048/engine"
+	"2048/settings"
+)
+
+func TestScoresAdd(t *testing.T) {
+	sc := &Scores{}
+	for i := range MaxScores {
+		if rank := sc.Add(ScoreEntry{Score: (i + 1) * 100, Seed: uint64(i)}); rank != 0 {
+			t.Fatalf("Add(%d) rank = %d; want 0", (i+1)*100, rank)
+		}
+	}
+	if sc.Best != MaxScores*100 {
+		t.Errorf("Best = %d; want %d", sc.Best, MaxScores*100)
+	}
+
+	// Too low to enter a full table
+	if rank := sc.Add(ScoreEntry{Score: 50, Seed: 100}); rank != -1 {
+		t.Errorf("Add(50) rank = %d; want -1", rank)
+	}
+
+	// Ties rank after existing entries
+	if rank := sc.Add(ScoreEntry{Score: 500, Seed: 101}); rank != 6 {
+		t.Errorf("Add(500) rank = %d; want 6", rank)
+	}
+	if len(sc.Entries) != MaxScores {
+		t.Errorf("len(Entries) = %d; want %d", len(sc.Entries), MaxScores)
+	}
+}
+
+func TestScoresAddSameGame(t *testing.T) {
+	sc := &Scores{}
+	sc.Add(ScoreEntry{Score: 300, Seed: 1, Rows: 4, Columns: 4})
+
+	// A worse result for the same game is ignored
+	if rank := sc.Add(ScoreEntry{Score: 200, Seed: 1, Rows: 4, Columns: 4}); rank != -1 {
+		t.Errorf("worse result rank = %d; want -1", rank)
+	}
+	// A better result replaces it
+	if rank := sc.Add(ScoreEntry{Score: 400, Seed: 1, Rows: 4, Columns: 4}); rank != 0 {
+		t.Errorf("better result rank = %d; want 0", rank)
+	}
+	// The same seed on another board size is a different game
+	sc.Add(ScoreEntry{Score: 100, Seed: 1, Rows: 5, Columns: 5})
+
+	if len(sc.Entries) != 2 || sc.Entries[0].Score != 400 {
+		t.Errorf("Entries = %+v", sc.Entries)
+	}
+}
+
+func TestSaveLoadScores(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+
+	sc, err := s.LoadScores()
+	if err != nil || sc.Best != 0 || len(sc.Entries) != 0 {
+		t.Fatalf("LoadScores() on empty dir = %+v, %v", sc, err)
+	}
+
+	sc.Add(ScoreEntry{
+		Score:    1234,
+		MaxTile:  128,
+		Moves:    99,
+		Duration: 3 * time.Minute,
+		Date:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Rows:     4,
+		Columns:  4,
+	})
+	if err := s.SaveScores(sc); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := s.LoadScores()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, sc) {
+		t.Errorf("LoadScores() = %+v; want %+v", loaded, sc)
+	}
+}
+
+func TestLoadScoresCorrupt(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	if err := os.WriteFile(s.path(scoresFile), []byte("{oops"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := s.LoadScores()
+	if err == nil {
+		t.Error("LoadScores() on a corrupt file returned no error")
+	}
+	if sc == nil || sc.Best != 0 || len(sc.Entries) != 0 {
+		t.Errorf("LoadScores() = %+v; want an empty table", sc)
+	}
+	if _, err := os.Stat(s.path(scoresFile + ".corrupt")); err != nil {
+		t.Errorf("corrupt file was not moved aside: %v", err)
+	}
+}
+
+func TestLoadScoresNormalizes(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	data := `{"best": 10, "entries": [{"score": 20}, {"score": 80}, {"score": 40}]}`
+	if err := os.WriteFile(s.path(scoresFile), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := s.LoadScores()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Best != 80 || sc.Entries[0].Score != 80 || sc.Entries[2].Score != 20 {
+		t.Errorf("LoadScores() = %+v", sc)
+	}
+}
+
+func TestRanked(t *testing.T) {
+	if !Ranked(engine.NewGame(4, 4)) {
+		t.Error("Ranked() = false for a classic game")
+	}
+	assisted := engine.NewGame(4, 4)
+	assisted.Assisted = true
+	for name, g := range map[string]*engine.Game{
+		"practice": engine.NewGame(4, 4, engine.WithFourChance(0.5)),
+		"reroll":   engine.NewGame(4, 4, engine.WithUndoReroll(true)),
+		"assisted": assisted,
+		"rules":    engine.NewGame(4, 4, engine.WithRules(engine.Threes)),
+		"walls":    engine.NewGame(4, 4, engine.WithRandomWalls(1)),
+	} {
+		if Ranked(g) {
+			t.Errorf("%s: Ranked() = true", name)
+		}
+	}
+}
+
+func TestScoresChallenges(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	sc := &Scores{}
+	sc.Add(ScoreEntry{Score: 100, Seed: 1})
+	sc.Challenge(settings.ChallengeTimed).Add(ScoreEntry{Score: 300, Seed: 2})
+	sc.Challenge(settings.ChallengeMoves).Add(ScoreEntry{Score: 200, Seed: 3})
+
+	if sc.Best != 100 || sc.Challenge(settings.ChallengeNone) != &sc.Table {
+		t.Errorf("regular table = %+v; want only the regular game", sc.Table)
+	}
+	if err := s.SaveScores(sc); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := s.LoadScores()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, sc) {
+		t.Errorf("LoadScores() = %+v; want %+v", loaded, sc)
+	}
+	if timed := loaded.Challenge(settings.ChallengeTimed); timed.Best != 300 || len(timed.Entries) != 1 {
+		t.Errorf("timed table = %+v", timed)
+	}
+}
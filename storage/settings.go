@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"errors"
+	"os"
+
+	"2048/settings"
+)
+
+const settingsFile = "settings.json"
+
+// LoadSettings reads the user's preferences. Missing fields, or a missing
+// file, yield the defaults and out-of-range values are normalized.
+// A corrupt file yields the defaults and an error.
+func (s *Store) LoadSettings() (settings.Settings, error) {
+	prefs := settings.Default()
+	err := s.readJSON(settingsFile, &prefs)
+	if errors.Is(err, os.ErrNotExist) {
+		return settings.Default(), nil
+	}
+	if err != nil {
+		return settings.Default(), err
+	}
+	prefs.Normalize()
+	return prefs, nil
+}
+
+// SaveSettings writes the user's preferences.
+func (s *Store) SaveSettings(prefs settings.Settings) error {
+	return s.writeJSON(settingsFile, prefs)
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"2048/settings"
+)
+
+func TestSettings(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	prefs, err := s.LoadSettings()
+	if err != nil || !reflect.DeepEqual(prefs, settings.Default()) {
+		t.Fatalf("LoadSettings() = %+v, %v in an empty directory; want the defaults", prefs, err)
+	}
+
+	prefs.Rows, prefs.Columns = 5, 6
+	prefs.Volume = 0.25
+	prefs.Keys = map[string][]string{"left": {"ArrowLeft", "H"}, "undo": {"Ctrl+Z"}}
+	prefs.Gamepad = map[string][]string{"confirm": {"A"}}
+	if err := s.SaveSettings(prefs); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, prefs) {
+		t.Errorf("LoadSettings() = %+v; want %+v", got, prefs)
+	}
+}
+
+func TestSettingsPartialFile(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+
+	// Missing fields keep their defaults, invalid ones are reset
+	data := `{"rows": 6, "columns": 6, "animation": "warp", "future": 1}`
+	if err := os.WriteFile(s.path(settingsFile), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := settings.Default()
+	want.Rows, want.Columns = 6, 6
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadSettings() = %+v; want %+v", got, want)
+	}
+
+	if err := os.WriteFile(s.path(settingsFile), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.LoadSettings(); err == nil || !reflect.DeepEqual(got, settings.Default()) {
+		t.Errorf("LoadSettings() = %+v, %v for a corrupt file; want the defaults and an error", got, err)
+	}
+}
@@ -0,0 +1,83 @@
+// Package storage persists game data under the user's config directory.
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Store reads and writes the game's files inside a directory.
+type Store struct {
+	Dir string
+}
+
+// Open returns a Store rooted in the user's config directory (e.g. ~/.config/2048).
+func Open() (*Store, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("storage: locating config dir: %w", err)
+	}
+	return &Store{Dir: filepath.Join(base, "2048")}, nil
+}
+
+// path returns the full path of a file inside the store.
+func (s *Store) path(name string) string {
+	return filepath.Join(s.Dir, name)
+}
+
+// readJSON decodes the named file into v.
+// Errors wrap os.ErrNotExist when the file doesn't exist.
+func (s *Store) readJSON(name string, v any) error {
+	b, err := os.ReadFile(s.path(name))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("storage: decoding %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeJSON encodes v into the named file.
+func (s *Store) writeJSON(name string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("storage: encoding %s: %w", name, err)
+	}
+	return s.writeFile(name, b)
+}
+
+// writeFile replaces the named file with b, creating its directory if needed.
+// NOTE: The data is written to a temporary file first and renamed over the
+// target, so a crash mid-write never leaves a truncated file behind.
+func (s *Store) writeFile(name string, b []byte) error {
+	dir := filepath.Dir(s.path(name))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(name))
+}
+
+// remove deletes the named file, ignoring files that don't exist.
+func (s *Store) remove(name string) error {
+	if err := os.Remove(s.path(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
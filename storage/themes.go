@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"2048/theme"
+)
+
+// themesDir holds the user's theme files, see theme.Read. Fonts they name
+// are looked up next to them.
+const themesDir = "themes"
+
+// LoadThemes reads the user's themes, sorted by file name. A missing
+// directory yields no themes. Broken themes are skipped and reported
+// together in the error, so one bad file doesn't hide the others.
+func (s *Store) LoadThemes() ([]theme.Theme, error) {
+	entries, err := os.ReadDir(s.path(themesDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var themes []theme.Theme
+	var errs []error
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		t, err := s.loadTheme(filepath.Join(themesDir, e.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		themes = append(themes, t)
+	}
+	return themes, errors.Join(errs...)
+}
+
+// loadTheme reads the named theme file and its font.
+func (s *Store) loadTheme(name string) (theme.Theme, error) {
+	f, err := os.Open(s.path(name))
+	if err != nil {
+		return theme.Theme{}, err
+	}
+	defer f.Close()
+
+	t, err := theme.Read(f)
+	if err != nil {
+		return theme.Theme{}, fmt.Errorf("storage: reading %s: %w", name, err)
+	}
+	if t.Font != "" {
+		t.FontData, err = os.ReadFile(s.path(filepath.Join(themesDir, t.Font)))
+		if err != nil {
+			return theme.Theme{}, fmt.Errorf("storage: font of %s: %w", name, err)
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadThemes(t *testing.T) {
+	s := &Store{Dir: t.TempDir()}
+	if themes, err := s.LoadThemes(); err != nil || len(themes) != 0 {
+		t.Fatalf("LoadThemes() = %v, %v without a themes directory; want nothing", themes, err)
+	}
+
+	dir := s.path(themesDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		"b.json":      `{"name": "ocean", "background": "#102030"}`,
+		"a.json":      `{"name": "typewriter", "font": "type.ttf"}`,
+		"type.ttf":    "font bytes",
+		"broken.json": `{"name": "broken", "text": "red"}`,
+		"nofont.json": `{"name": "nofont", "font": "missing.ttf"}`,
+		"notes.txt":   "not a theme",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	themes, err := s.LoadThemes()
+	if err == nil {
+		t.Error("broken themes weren't reported")
+	}
+	if len(themes) != 2 || themes[0].Name != "typewriter" || themes[1].Name != "ocean" {
+		t.Fatalf("LoadThemes() = %+v; want typewriter and ocean", themes)
+	}
+	if string(themes[0].FontData) != "font bytes" {
+		t.Errorf("font data = %q", themes[0].FontData)
+	}
+}
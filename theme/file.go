@@ -0,0 +1,109 @@
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// fileJSON is the format of theme files, e.g.:
+//
+//	{
+//	  "name": "ocean",
+//	  "background": "#1b3a4b",
+//	  "tiles": {
+//	    "2": {"background": "#e0f7fa", "foreground": "#004d60"},
+//	    "4": {"background": "#b2ebf2", "foreground": "#004d60"}
+//	  },
+//	  "font": "ocean.ttf"
+//	}
+//
+// Tiles are keyed by value, 0 being the empty cell. Anything left out is
+// taken from Classic, and tiles past the last one get generated colors.
+type fileJSON struct {
+	Name       string                   `json:"name"`
+	Background string                   `json:"background"`
+	Widget     string                   `json:"widget"`
+	WidgetText string                   `json:"widget_text"`
+	Text       string                   `json:"text"`
+	Accent     string                   `json:"accent"`
+	Tiles      map[string]tileColorJSON `json:"tiles"`
+	Font       string                   `json:"font"` // path relative to the theme file
+}
+
+type tileColorJSON struct {
+	Background string `json:"background"`
+	Foreground string `json:"foreground"`
+}
+
+// Read parses a theme file. The font file it names, if any, is not read.
+func Read(r io.Reader) (Theme, error) {
+	var f fileJSON
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return Theme{}, fmt.Errorf("theme: %w", err)
+	}
+	if f.Name == "" {
+		return Theme{}, fmt.Errorf("theme: missing name")
+	}
+
+	t := Classic
+	t.Name, t.Font = f.Name, f.Font
+	t.Tiles = slices.Clone(Classic.Tiles)
+	for _, c := range []struct {
+		hex string
+		dst *color.RGBA
+	}{
+		{f.Background, &t.Background},
+		{f.Widget, &t.Widget},
+		{f.WidgetText, &t.WidgetText},
+		{f.Text, &t.Text},
+		{f.Accent, &t.Accent},
+	} {
+		if err := parseColor(c.hex, c.dst); err != nil {
+			return Theme{}, err
+		}
+	}
+
+	for key, tc := range f.Tiles {
+		v, err := strconv.Atoi(key)
+		if err != nil || v < 0 || v&(v-1) != 0 || v == 1 {
+			return Theme{}, fmt.Errorf("theme: tile %q isn't 0 or a power of two", key)
+		}
+		rank := Rank(v)
+		for len(t.Tiles) <= rank {
+			t.Tiles = append(t.Tiles, t.Tile(len(t.Tiles)))
+		}
+		if err := parseColor(tc.Background, &t.Tiles[rank].Background); err != nil {
+			return Theme{}, err
+		}
+		if err := parseColor(tc.Foreground, &t.Tiles[rank].Foreground); err != nil {
+			return Theme{}, err
+		}
+	}
+	return t, nil
+}
+
+// parseColor parses "#rrggbb" or "#rrggbbaa" into dst.
+// An empty string leaves dst unchanged.
+func parseColor(s string, dst *color.RGBA) error {
+	if s == "" {
+		return nil
+	}
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || (len(hex) != 6 && len(hex) != 8) {
+		return fmt.Errorf("theme: invalid color %q, want #rrggbb", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return fmt.Errorf("theme: invalid color %q, want #rrggbb", s)
+	}
+	*dst = color.RGBA{uint8(n >> 24), uint8(n >> 16), uint8(n >> 8), uint8(n)}
+	return nil
+}
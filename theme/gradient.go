@@ -0,0 +1,81 @@
+package theme
+
+import (
+	"image/color"
+	"math"
+)
+
+// Each rank past a theme's last tile turns the hue and darkens the color a
+// little more, so every tile keeps a color of its own.
+const (
+	gradientHueStep   = 47.0 // degrees, coprime with 360 so hues take long to repeat
+	gradientLightStep = 0.04
+	gradientMinLight  = 0.25
+)
+
+// gradient returns the colors of the tile steps ranks past last.
+func gradient(last TileColor, steps int) TileColor {
+	h, s, l := toHSL(last.Background)
+	h = math.Mod(h+gradientHueStep*float64(steps), 360)
+	s = max(s, 0.5)
+	l = max(l-gradientLightStep*float64(steps), gradientMinLight)
+
+	fg := fgLight
+	if l > 0.65 {
+		fg = fgDark
+	}
+	return TileColor{Background: fromHSL(h, s, l), Foreground: fg}
+}
+
+// toHSL converts an opaque color to hue (degrees), saturation and lightness.
+func toHSL(c color.RGBA) (h, s, l float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	hi, lo := max(r, g, b), min(r, g, b)
+	l = (hi + lo) / 2
+	if hi == lo {
+		return 0, 0, l
+	}
+
+	d := hi - lo
+	if l > 0.5 {
+		s = d / (2 - hi - lo)
+	} else {
+		s = d / (hi + lo)
+	}
+	switch hi {
+	case r:
+		h = math.Mod((g-b)/d+6, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h * 60, s, l
+}
+
+// fromHSL is the inverse of toHSL.
+func fromHSL(h, s, l float64) color.RGBA {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = c, x, 0
+	case h < 120:
+		r, g, b = x, c, 0
+	case h < 180:
+		r, g, b = 0, c, x
+	case h < 240:
+		r, g, b = 0, x, c
+	case h < 300:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	channel := func(v float64) uint8 {
+		return uint8(math.Round((v + m) * 255))
+	}
+	return color.RGBA{channel(r), channel(g), channel(b), 255}
+}
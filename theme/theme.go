@@ -0,0 +1,179 @@
+// Package theme holds the game's color palettes, shared by every frontend.
+package theme
+
+import (
+	"image/color"
+	"math/bits"
+)
+
+// TileColor is the look of one tile value.
+type TileColor struct {
+	Background color.RGBA
+	Foreground color.RGBA
+}
+
+// Theme is the full look of the game.
+type Theme struct {
+	Name       string
+	Background color.RGBA // behind the board, the HUD and the menus
+	Widget     color.RGBA // boxes of the HUD
+	WidgetText color.RGBA // titles inside the boxes
+	Text       color.RGBA // menu and overlay text
+	Accent     color.RGBA // hints, selections and progress bars
+
+	// Tiles are indexed by rank: 0 is the empty cell, 1 the smallest tile
+	// (the 2 in classic games), 2 the next one and so on, see
+	// engine.Rules.Rank. Ranks past the end get generated colors.
+	Tiles []TileColor
+
+	Font     string // font file of the theme, empty for the built-in font
+	FontData []byte // contents of Font, filled in by whoever loads the theme
+}
+
+// Rank returns the rank of a tile value: its base-2 logarithm, or 0 for an
+// empty cell. Values that aren't powers of two get the rank of the next
+// smaller power of two.
+func Rank(v int) int {
+	if v <= 0 {
+		return 0
+	}
+	return bits.Len(uint(v)) - 1
+}
+
+// Tile returns the colors of the tiles of a rank, see Theme.Tiles.
+func (t *Theme) Tile(rank int) TileColor {
+	if rank < 0 {
+		rank = 0
+	}
+	if rank < len(t.Tiles) {
+		return t.Tiles[rank]
+	}
+	if len(t.Tiles) == 0 {
+		return Classic.Tile(rank)
+	}
+	last := len(t.Tiles) - 1
+	return gradient(t.Tiles[last], rank-last)
+}
+
+// Value returns the colors of a tile value of a classic game, see Rank.
+func (t *Theme) Value(v int) TileColor {
+	return t.Tile(Rank(v))
+}
+
+// Find returns the theme with the given name, or Classic.
+func Find(themes []Theme, name string) Theme {
+	for _, t := range themes {
+		if t.Name == name {
+			return t
+		}
+	}
+	return Classic
+}
+
+// Light numbers (2, 4) have dark text, darker tiles have light text.
+var (
+	fgDark  = color.RGBA{119, 110, 101, 255}
+	fgLight = color.RGBA{249, 246, 242, 255}
+	white   = color.RGBA{255, 255, 255, 255}
+	black   = color.RGBA{0, 0, 0, 255}
+)
+
+// Builtin lists the themes shipped with the game, Classic first.
+var Builtin = []Theme{Classic, Dark, HighContrast, Colorblind}
+
+// Classic has the colors of the original 2048.
+var Classic = Theme{
+	Name:       "classic",
+	Background: color.RGBA{187, 173, 160, 255},
+	Widget:     color.RGBA{143, 122, 102, 255},
+	WidgetText: color.RGBA{238, 228, 218, 255},
+	Text:       white,
+	Accent:     color.RGBA{246, 94, 59, 255},
+	Tiles: []TileColor{
+		{Background: color.RGBA{205, 193, 180, 255}, Foreground: fgDark},  // empty
+		{Background: color.RGBA{238, 228, 218, 255}, Foreground: fgDark},  // 2
+		{Background: color.RGBA{237, 224, 200, 255}, Foreground: fgDark},  // 4
+		{Background: color.RGBA{242, 177, 121, 255}, Foreground: fgLight}, // 8
+		{Background: color.RGBA{245, 149, 99, 255}, Foreground: fgLight},  // 16
+		{Background: color.RGBA{246, 124, 95, 255}, Foreground: fgLight},  // 32
+		{Background: color.RGBA{246, 94, 59, 255}, Foreground: fgLight},   // 64
+		{Background: color.RGBA{237, 207, 114, 255}, Foreground: fgLight}, // 128
+		{Background: color.RGBA{237, 204, 97, 255}, Foreground: fgLight},  // 256
+		{Background: color.RGBA{237, 200, 80, 255}, Foreground: fgLight},  // 512
+		{Background: color.RGBA{237, 197, 63, 255}, Foreground: fgLight},  // 1024
+		{Background: color.RGBA{237, 194, 46, 255}, Foreground: fgLight},  // 2048
+	},
+}
+
+// Dark is a low-light palette with cool tiles.
+var Dark = Theme{
+	Name:       "dark",
+	Background: color.RGBA{30, 32, 38, 255},
+	Widget:     color.RGBA{52, 56, 66, 255},
+	WidgetText: color.RGBA{160, 166, 180, 255},
+	Text:       color.RGBA{230, 232, 238, 255},
+	Accent:     color.RGBA{255, 138, 76, 255},
+	Tiles: []TileColor{
+		{Background: color.RGBA{44, 47, 56, 255}, Foreground: white},
+		{Background: color.RGBA{72, 78, 94, 255}, Foreground: white},
+		{Background: color.RGBA{66, 90, 120, 255}, Foreground: white},
+		{Background: color.RGBA{48, 110, 150, 255}, Foreground: white},
+		{Background: color.RGBA{36, 130, 160, 255}, Foreground: white},
+		{Background: color.RGBA{30, 150, 150, 255}, Foreground: white},
+		{Background: color.RGBA{40, 160, 120, 255}, Foreground: white},
+		{Background: color.RGBA{120, 90, 170, 255}, Foreground: white},
+		{Background: color.RGBA{150, 80, 170, 255}, Foreground: white},
+		{Background: color.RGBA{180, 70, 150, 255}, Foreground: white},
+		{Background: color.RGBA{200, 70, 110, 255}, Foreground: white},
+		{Background: color.RGBA{225, 90, 60, 255}, Foreground: white},
+	},
+}
+
+// HighContrast maximizes the contrast between tiles, text and background.
+var HighContrast = Theme{
+	Name:       "high-contrast",
+	Background: black,
+	Widget:     color.RGBA{40, 40, 40, 255},
+	WidgetText: white,
+	Text:       white,
+	Accent:     color.RGBA{255, 255, 0, 255},
+	Tiles: []TileColor{
+		{Background: color.RGBA{24, 24, 24, 255}, Foreground: white},
+		{Background: white, Foreground: black},
+		{Background: color.RGBA{255, 255, 0, 255}, Foreground: black},
+		{Background: color.RGBA{0, 255, 255, 255}, Foreground: black},
+		{Background: color.RGBA{0, 255, 0, 255}, Foreground: black},
+		{Background: color.RGBA{255, 128, 0, 255}, Foreground: black},
+		{Background: color.RGBA{255, 0, 255, 255}, Foreground: black},
+		{Background: color.RGBA{0, 0, 255, 255}, Foreground: white},
+		{Background: color.RGBA{255, 0, 0, 255}, Foreground: white},
+		{Background: color.RGBA{0, 128, 0, 255}, Foreground: white},
+		{Background: color.RGBA{128, 0, 128, 255}, Foreground: white},
+		{Background: color.RGBA{128, 64, 0, 255}, Foreground: white},
+	},
+}
+
+// Colorblind uses the Okabe-Ito palette, which stays distinguishable with
+// the common forms of color blindness, with lightness falling as tiles grow.
+var Colorblind = Theme{
+	Name:       "colorblind",
+	Background: color.RGBA{187, 180, 170, 255},
+	Widget:     color.RGBA{90, 90, 90, 255},
+	WidgetText: color.RGBA{230, 230, 230, 255},
+	Text:       white,
+	Accent:     color.RGBA{0, 114, 178, 255},
+	Tiles: []TileColor{
+		{Background: color.RGBA{210, 205, 198, 255}, Foreground: black},
+		{Background: color.RGBA{250, 250, 250, 255}, Foreground: black},
+		{Background: color.RGBA{240, 228, 66, 255}, Foreground: black},  // yellow
+		{Background: color.RGBA{86, 180, 233, 255}, Foreground: black},  // sky blue
+		{Background: color.RGBA{230, 159, 0, 255}, Foreground: black},   // orange
+		{Background: color.RGBA{0, 158, 115, 255}, Foreground: white},   // bluish green
+		{Background: color.RGBA{204, 121, 167, 255}, Foreground: black}, // reddish purple
+		{Background: color.RGBA{0, 114, 178, 255}, Foreground: white},   // blue
+		{Background: color.RGBA{213, 94, 0, 255}, Foreground: white},    // vermillion
+		{Background: color.RGBA{100, 100, 100, 255}, Foreground: white},
+		{Background: color.RGBA{60, 60, 60, 255}, Foreground: white},
+		{Background: black, Foreground: white},
+	},
+}
@@ -0,0 +1,95 @@
+package theme
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestRank(t *testing.T) {
+	for v, want := range map[int]int{0: 0, 2: 1, 4: 2, 2048: 11, 1 << 17: 17, 3: 1} {
+		if got := Rank(v); got != want {
+			t.Errorf("Rank(%d) = %d; want %d", v, got, want)
+		}
+	}
+}
+
+func TestBuiltinThemes(t *testing.T) {
+	for _, th := range Builtin {
+		if len(th.Tiles) != 12 {
+			t.Errorf("%s defines %d tiles; want empty to 2048", th.Name, len(th.Tiles))
+		}
+		if Find(Builtin, th.Name).Name != th.Name {
+			t.Errorf("Find(%q) didn't find it", th.Name)
+		}
+	}
+	if Find(Builtin, "missing").Name != Classic.Name {
+		t.Error("Find doesn't fall back to Classic")
+	}
+}
+
+func TestGeneratedTiles(t *testing.T) {
+	// Past 2048, every rank gets an opaque color of its own
+	seen := map[color.RGBA]bool{}
+	for rank := 11; rank <= 30; rank++ {
+		c := Classic.Tile(rank)
+		if c.Background.A != 255 || c.Foreground.A != 255 {
+			t.Errorf("rank %d isn't opaque: %+v", rank, c)
+		}
+		if seen[c.Background] {
+			t.Errorf("rank %d reuses color %v", rank, c.Background)
+		}
+		seen[c.Background] = true
+	}
+	if Classic.Value(2048) != Classic.Tiles[11] || Classic.Value(4096) == Classic.Tiles[11] {
+		t.Error("Value doesn't map 2048 to the last tile and 4096 past it")
+	}
+}
+
+func TestHSLRoundTrip(t *testing.T) {
+	for _, tile := range Classic.Tiles {
+		c := tile.Background
+		if got := fromHSL(toHSL(c)); got != c {
+			t.Errorf("fromHSL(toHSL(%v)) = %v", c, got)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	th, err := Read(strings.NewReader(`{
+		"name": "ocean",
+		"background": "#102030",
+		"tiles": {"2": {"background": "#ffffff80"}, "8192": {"background": "#010203"}},
+		"font": "ocean.ttf"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if th.Name != "ocean" || th.Font != "ocean.ttf" || th.Background != (color.RGBA{0x10, 0x20, 0x30, 0xff}) {
+		t.Errorf("got %+v", th)
+	}
+	if th.Tiles[1].Background != (color.RGBA{255, 255, 255, 0x80}) || th.Tiles[1].Foreground != Classic.Tiles[1].Foreground {
+		t.Errorf("tile 2 = %+v", th.Tiles[1])
+	}
+	if len(th.Tiles) != 14 || th.Tiles[13].Background != (color.RGBA{1, 2, 3, 255}) {
+		t.Errorf("tile 8192 = %+v (%d tiles)", th.Tiles[len(th.Tiles)-1], len(th.Tiles))
+	}
+	if th.Widget != Classic.Widget {
+		t.Error("missing colors aren't taken from Classic")
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	for _, s := range []string{
+		`{`,
+		`{"background": "#000000"}`,
+		`{"name": "x", "background": "black"}`,
+		`{"name": "x", "text": "#12345"}`,
+		`{"name": "x", "tiles": {"3": {"background": "#000000"}}}`,
+		`{"name": "x", "tiles": {"2": {"foreground": "#zzzzzz"}}}`,
+	} {
+		if _, err := Read(strings.NewReader(s)); err == nil {
+			t.Errorf("Read(%s) succeeded; want an error", s)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"math"
+
+	"2048/engine"
+)
+
+// AnimSpeed selects how long the tile animations of a move last.
+type AnimSpeed int
+
+const (
+	AnimOff AnimSpeed = iota // moves are applied instantly
+	AnimFast
+	AnimNormal
+	AnimSlow
+)
+
+// AnimSpeeds lists the selectable speeds in menu order.
+var AnimSpeeds = []AnimSpeed{AnimOff, AnimFast, AnimNormal, AnimSlow}
+
+func (s AnimSpeed) String() string {
+	switch s {
+	case AnimOff:
+		return "Off"
+	case AnimFast:
+		return "Fast"
+	case AnimNormal:
+		return "Normal"
+	case AnimSlow:
+		return "Slow"
+	}
+	return "?"
+}
+
+// frames returns the length of a move animation in updates (at 60 TPS).
+func (s AnimSpeed) frames() int {
+	switch s {
+	case AnimFast:
+		return 6
+	case AnimNormal:
+		return 10
+	case AnimSlow:
+		return 18
+	}
+	return 0
+}
+
+// slidePhase is the fraction of an animation spent sliding tiles;
+// the rest is used for merge pops and spawn growth.
+const slidePhase = 0.6
+
+// animation tweens the board from its state before a move to the current one.
+type animation struct {
+	before  [][]int                     // board before the move
+	targets map[engine.Cell]engine.Cell // where each moving tile goes
+	merged  map[engine.Cell]bool        // cells holding a merge result
+	spawned map[engine.Cell]bool        // cells holding the new tile
+	frame   int
+	frames  int
+}
+
+// newAnimation builds the animation of a turn from the events it produced.
+func newAnimation(before [][]int, events []engine.Event, frames int) *animation {
+	anim := &animation{
+		before:  before,
+		targets: map[engine.Cell]engine.Cell{},
+		merged:  map[engine.Cell]bool{},
+		spawned: map[engine.Cell]bool{},
+		frames:  frames,
+	}
+	for _, e := range events {
+		switch e.Kind {
+		case engine.EventSlide:
+			anim.targets[e.From] = e.To
+		case engine.EventMerge:
+			anim.targets[e.From] = e.To
+			anim.merged[e.To] = true
+		case engine.EventSpawn:
+			anim.spawned[e.To] = true
+		}
+	}
+	return anim
+}
+
+// advance moves the animation one update forward.
+// Returns false once it is over.
+func (anim *animation) advance() bool {
+	anim.frame++
+	return anim.frame < anim.frames
+}
+
+// progress returns how far along the animation is, in [0, 1].
+func (anim *animation) progress() float64 {
+	return min(float64(anim.frame)/float64(anim.frames), 1)
+}
+
+// sliding reports whether tiles are still travelling, and how far they went.
+func (anim *animation) sliding() (bool, float64) {
+	t := anim.progress()
+	if t >= slidePhase {
+		return false, 1
+	}
+	return true, easeOut(t / slidePhase)
+}
+
+// scale returns the size factor of the tile at cell once tiles have landed:
+// merge results pop slightly larger, new tiles grow from nothing.
+func (anim *animation) scale(cell engine.Cell) float64 {
+	p := (anim.progress() - slidePhase) / (1 - slidePhase)
+	p = max(p, 0)
+	switch {
+	case anim.spawned[cell]:
+		return easeOut(p)
+	case anim.merged[cell]:
+		return 1 + 0.2*math.Sin(math.Pi*p)
+	}
+	return 1
+}
+
+// easeOut decelerates towards the end of a transition.
+func easeOut(t float64) float64 {
+	return 1 - (1-t)*(1-t)
+}
@@ -1,7 +1,16 @@
 package ui
 
 import (
+	"log"
+	"slices"
+	"time"
+
 	"2048/engine"
+	"2048/replay"
+	"2048/settings"
+	"2048/solver"
+	"2048/storage"
+	"2048/theme"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -9,19 +18,110 @@ import (
 type App struct {
 	scene     Scene
 	engine    *engine.Game
-	bestScore int
+	scores    *storage.Scores   // best score and high-score table, never nil
+	prefs     settings.Settings // user preferences, saved with savePrefs
+	menuIndex int               // highlighted entry of the menu
+	winIndex  int               // highlighted option of the win overlay
+
+	settingsIndex int           // highlighted row of the settings screen
+	scoresIndex   int           // table of the high-score screen, index into settings.Challenges
+	themes        []theme.Theme // built-in themes, then the user's
+
+	animSpeed AnimSpeed          // duration of move animations, from prefs
+	anim      *animation         // animation of the last move, nil when idle
+	queued    []engine.Direction // moves buffered while animating
+
+	solver   solver.Config    // search settings for hints and autoplay
+	hint     engine.Direction // suggested direction, valid if hasHint
+	hasHint  bool
+	autoplay bool // the solver plays on its own
+	autoRate int  // index into AutoplayRates
+	autoWait int  // updates left before the next autoplay move
+
+	store      *storage.Store // nil if the config dir is unavailable
+	hasSave    bool           // a saved game can be continued from the menu
+	hasReplays bool           // replays of finished games can be watched from the menu
+
+	input   *input         // keyboard and gamepad actions
+	pointer pointerTracker // mouse drag or touch in progress
+	gesture gesture        // gesture completed this update
+
+	replay *replay.Replay // recording of the current game, nil if not recorded
+	player *replayPlayer  // playback of the replay scene
+
+	controls *controlsScreen // state of the rebinding screen
 }
 
 // NewApp initializes a new App instance with the initial scene set to SceneMenu.
 func NewApp() *App {
-	return &App{
-		scene:  SceneMenu,
-		engine: nil, // Engine will be initialized lazily (at menu start)
+	store, err := storage.Open()
+	if err != nil {
+		// Keep playing without persistence
+		log.Println(err)
+		store = nil
+	}
+
+	a := &App{
+		scene:     SceneMenu,
+		engine:    nil, // Engine will be initialized lazily (at menu start)
+		scores:    &storage.Scores{},
+		prefs:     settings.Default(),
+		animSpeed: AnimNormal,
+		solver:    solver.DefaultConfig(),
+		input:     newInput(),
+		autoRate:  defaultAutoplayRate,
+		store:     store,
+		themes:    theme.Builtin,
 	}
+	if store != nil {
+		a.hasSave = store.HasSavedGame()
+		names, _ := store.ListReplays()
+		a.hasReplays = len(names) > 0
+
+		// A broken table is reset rather than stopping the game from starting
+		scores, err := store.LoadScores()
+		if err != nil {
+			log.Println(err)
+		}
+		a.scores = scores
+
+		// A broken file falls back to the defaults
+		a.prefs, err = store.LoadSettings()
+		if err != nil {
+			log.Println(err)
+		}
+
+		// Broken themes are left out of the settings
+		themes, err := store.LoadThemes()
+		if err != nil {
+			log.Println(err)
+		}
+		a.themes = append(slices.Clone(theme.Builtin), themes...)
+	}
+	a.applyPrefs()
+	return a
 }
 
 // Update processes the current scene and updates the game state accordingly.
 func (a *App) Update() error {
+	if ebiten.IsWindowBeingClosed() {
+		// Keep the unfinished game around for the next launch
+		a.saveGame()
+		return ebiten.Termination
+	}
+
+	a.input.update()
+
+	// Follow presses in every scene, so none is left half-tracked
+	// when the scene changes in the middle of a swipe
+	a.gesture = a.pointer.update()
+
+	// NOTE: Not while a key is being bound, so F11 can be rebound
+	capturing := a.scene == SceneControls && a.controls.capturing
+	if a.input.justPressed(ActionFullscreen) && !capturing {
+		a.toggleFullscreen()
+	}
+
 	switch a.scene {
 	case SceneMenu:
 		updateMenu(a)
@@ -29,6 +129,16 @@ func (a *App) Update() error {
 		updatePlay(a)
 	case SceneGameOver:
 		updateGameOver(a)
+	case SceneHighScores:
+		updateHighScores(a)
+	case SceneWin:
+		updateWin(a)
+	case SceneReplay:
+		updateReplay(a)
+	case SceneControls:
+		updateControls(a)
+	case SceneSettings:
+		updateSettings(a)
 	}
 	return nil
 }
@@ -37,17 +147,96 @@ func (a *App) Update() error {
 func (a *App) Draw(screen *ebiten.Image) {
 	switch a.scene {
 	case SceneMenu:
-		drawMenu(screen, a.bestScore)
+		drawMenu(screen, a.input.bindings, a.scores.Challenge(a.prefs.Challenge).Best, a.menuItems(), a.menuIndex, a.prefs)
 	case ScenePlay:
-		drawPlay(screen, a.engine)
-		drawHUD(screen, a.engine.Score, a.bestScore)
+		drawPlay(screen, a.engine, a.anim)
+		if a.hasHint {
+			drawHint(screen, a.engine, a.hint)
+		}
+		drawHUD(screen, a.input.bindings, a.engine, a.best(a.engine), a.engine.UndoCount(), a.playStatus())
 	case SceneGameOver:
-		drawPlay(screen, a.engine)           // show last board
-		drawGameOver(screen, a.engine.Score) // overlay + texts
+		reason, _ := a.engine.Over()
+		drawPlay(screen, a.engine, nil) // show last board
+		drawGameOver(screen, a.input.bindings, a.engine.Score, reason, a.canUndoGameOver(), a.replay != nil)
+	case SceneHighScores:
+		drawHighScores(screen, a.input.bindings, a.scores, a.scoresIndex)
+	case SceneWin:
+		drawPlay(screen, a.engine, nil) // show the winning board
+		drawHUD(screen, a.input.bindings, a.engine, a.best(a.engine), a.engine.UndoCount(), "")
+		drawWin(screen, a.engine.Target, a.winIndex)
+	case SceneReplay:
+		drawReplay(screen, a.input.bindings, a.player, a.best(a.player.frames[0].Game))
+	case SceneControls:
+		drawControls(screen, a.controls)
+	case SceneSettings:
+		drawSettings(screen, a.input.bindings, a.settingsRows(), a.settingsIndex)
 	}
 }
 
-// Layout returns the dimensions of the game screen.
-func (a *App) Layout(_, _ int) (int, int) {
-	return engine.ScreenWidth, engine.ScreenHeight
+// newGame starts a fresh engine using the board size selected in the menu.
+func (a *App) newGame() {
+	size := a.boardSize()
+	a.engine = engine.NewGame(size.Rows, size.Columns, a.prefs.GameOptions()...)
+	a.replay = replay.New(a.engine)
+}
+
+// saveGame stores the game in progress, if there is one, including a won
+// game whose player hasn't decided yet whether to keep going.
+func (a *App) saveGame() {
+	if a.store == nil || a.engine == nil || a.scene != ScenePlay && a.scene != SceneWin {
+		return
+	}
+	if err := a.store.SaveProgress(a.engine, a.replay); err != nil {
+		log.Println(err)
+	}
+	a.hasSave = a.store.HasSavedGame()
+}
+
+// loadGame resumes the saved game. Returns false if it couldn't be loaded.
+func (a *App) loadGame() bool {
+	if a.store == nil {
+		return false
+	}
+	// Keep recording where the saved game left off
+	g, r, err := a.store.LoadProgress()
+	if err != nil {
+		log.Println(err)
+	}
+	if g == nil {
+		a.hasSave = false
+		return false
+	}
+	a.engine, a.replay = g, r
+	return true
+}
+
+// deleteSave forgets the saved game, e.g. once it is over.
+func (a *App) deleteSave() {
+	if a.store == nil {
+		return
+	}
+	if err := a.store.DeleteProgress(); err != nil {
+		log.Println(err)
+	}
+	a.hasSave = false
+}
+
+// recordScore adds the finished game to its high-score table and saves it,
+// together with its replay, see storage.RecordGame.
+func (a *App) recordScore() {
+	if err := storage.RecordGame(a.store, a.scores, a.engine, a.replay, time.Now()); err != nil {
+		log.Println(err)
+	} else if a.store != nil && a.replay != nil {
+		a.hasReplays = true
+	}
+}
+
+// best returns the best score of the table a game competes in, or -1 if it
+// doesn't enter one (see storage.Ranked).
+func (a *App) best(g *engine.Game) int {
+	t := a.scores.TableFor(g)
+	if t == nil {
+		return -1
+	}
+	return t.Best
 }
@@ -0,0 +1,266 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// actionNames are the names of the actions in the settings file and on the
+// controls screen, in display order.
+var actionNames = [actionCount]string{
+	ActionLeft:        "left",
+	ActionUp:          "up",
+	ActionRight:       "right",
+	ActionDown:        "down",
+	ActionConfirm:     "confirm",
+	ActionBack:        "back",
+	ActionMenu:        "menu",
+	ActionRetry:       "retry",
+	ActionUndo:        "undo",
+	ActionRedo:        "redo",
+	ActionHint:        "hint",
+	ActionAutoplay:    "autoplay",
+	ActionSlower:      "slower",
+	ActionFaster:      "faster",
+	ActionWatch:       "watch",
+	ActionSeekBack:    "seek-back",
+	ActionSeekForward: "seek-forward",
+	ActionSeekStart:   "seek-start",
+	ActionSeekEnd:     "seek-end",
+	ActionFullscreen:  "fullscreen",
+}
+
+func (a Action) String() string {
+	return actionNames[a]
+}
+
+// parseAction returns the action with the given name.
+func parseAction(name string) (Action, bool) {
+	i := slices.Index(actionNames[:], name)
+	return Action(i), i >= 0
+}
+
+// context is a set of scenes, see actionContexts.
+type context int
+
+const (
+	ctxMenu context = 1 << iota
+	ctxPlay
+	ctxGameOver
+	ctxWin
+	ctxHighScores
+	ctxReplay
+
+	ctxAll = ctxMenu | ctxPlay | ctxGameOver | ctxWin | ctxHighScores | ctxReplay
+)
+
+// actionContexts lists where each action is read. Two actions may share an
+// input as long as they are never read in the same scene.
+var actionContexts = [actionCount]context{
+	ActionLeft:        ctxMenu | ctxPlay | ctxHighScores | ctxReplay,
+	ActionUp:          ctxMenu | ctxPlay | ctxWin | ctxReplay,
+	ActionRight:       ctxMenu | ctxPlay | ctxHighScores | ctxReplay,
+	ActionDown:        ctxMenu | ctxPlay | ctxWin | ctxReplay,
+	ActionConfirm:     ctxMenu | ctxWin | ctxHighScores | ctxReplay,
+	ActionBack:        ctxHighScores | ctxReplay,
+	ActionMenu:        ctxPlay | ctxGameOver | ctxHighScores | ctxReplay,
+	ActionRetry:       ctxGameOver,
+	ActionUndo:        ctxPlay | ctxGameOver,
+	ActionRedo:        ctxPlay,
+	ActionHint:        ctxPlay,
+	ActionAutoplay:    ctxPlay,
+	ActionSlower:      ctxPlay | ctxReplay,
+	ActionFaster:      ctxPlay | ctxReplay,
+	ActionWatch:       ctxGameOver,
+	ActionSeekBack:    ctxReplay,
+	ActionSeekForward: ctxReplay,
+	ActionSeekStart:   ctxReplay,
+	ActionSeekEnd:     ctxReplay,
+	ActionFullscreen:  ctxAll,
+}
+
+// String returns the name of a key binding, e.g. "Ctrl+Shift+Z".
+func (k keyBinding) String() string {
+	s := k.Key.String()
+	if k.Shift {
+		s = "Shift+" + s
+	}
+	if k.Ctrl {
+		s = "Ctrl+" + s
+	}
+	return s
+}
+
+// parseKeyBinding parses the name of a key binding, see keyBinding.String.
+func parseKeyBinding(s string) (keyBinding, error) {
+	var k keyBinding
+	for {
+		if rest, ok := strings.CutPrefix(s, "Ctrl+"); ok {
+			k.Ctrl, s = true, rest
+		} else if rest, ok := strings.CutPrefix(s, "Shift+"); ok {
+			k.Shift, s = true, rest
+		} else {
+			break
+		}
+	}
+	if err := k.Key.UnmarshalText([]byte(s)); err != nil {
+		return keyBinding{}, err
+	}
+	return k, nil
+}
+
+// gamepadButtonNames are the names of the standard gamepad buttons, Xbox style.
+var gamepadButtonNames = map[ebiten.StandardGamepadButton]string{
+	ebiten.StandardGamepadButtonRightBottom:      "A",
+	ebiten.StandardGamepadButtonRightRight:       "B",
+	ebiten.StandardGamepadButtonRightLeft:        "X",
+	ebiten.StandardGamepadButtonRightTop:         "Y",
+	ebiten.StandardGamepadButtonFrontTopLeft:     "LB",
+	ebiten.StandardGamepadButtonFrontTopRight:    "RB",
+	ebiten.StandardGamepadButtonFrontBottomLeft:  "LT",
+	ebiten.StandardGamepadButtonFrontBottomRight: "RT",
+	ebiten.StandardGamepadButtonCenterLeft:       "Select",
+	ebiten.StandardGamepadButtonCenterRight:      "Start",
+	ebiten.StandardGamepadButtonCenterCenter:     "Home",
+	ebiten.StandardGamepadButtonLeftStick:        "LS",
+	ebiten.StandardGamepadButtonRightStick:       "RS",
+	ebiten.StandardGamepadButtonLeftTop:          "DpadUp",
+	ebiten.StandardGamepadButtonLeftBottom:       "DpadDown",
+	ebiten.StandardGamepadButtonLeftLeft:         "DpadLeft",
+	ebiten.StandardGamepadButtonLeftRight:        "DpadRight",
+}
+
+// parseButton returns the gamepad button with the given name.
+func parseButton(name string) (ebiten.StandardGamepadButton, bool) {
+	for b, n := range gamepadButtonNames {
+		if strings.EqualFold(n, name) {
+			return b, true
+		}
+	}
+	return 0, false
+}
+
+// apply replaces the bindings of every action listed in the user's
+// settings. Unknown actions and inputs are skipped and reported.
+func (b bindings) apply(userKeys, userButtons map[string][]string) []error {
+	var errs []error
+	for name, keys := range userKeys {
+		action, ok := parseAction(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("bindings: unknown action %q", name))
+			continue
+		}
+		b.keys[action] = nil
+		for _, s := range keys {
+			k, err := parseKeyBinding(s)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("bindings: %s: %w", name, err))
+				continue
+			}
+			b.keys[action] = append(b.keys[action], k)
+		}
+	}
+	for name, buttons := range userButtons {
+		action, ok := parseAction(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("bindings: unknown action %q", name))
+			continue
+		}
+		b.buttons[action] = nil
+		for _, s := range buttons {
+			button, ok := parseButton(s)
+			if !ok {
+				errs = append(errs, fmt.Errorf("bindings: %s: unknown gamepad button %q", name, s))
+				continue
+			}
+			b.buttons[action] = append(b.buttons[action], button)
+		}
+	}
+	return errs
+}
+
+// export returns the key and button bindings in the form stored in the
+// settings file.
+func (b bindings) export() (keys, buttons map[string][]string) {
+	keys, buttons = map[string][]string{}, map[string][]string{}
+	for action := range actionCount {
+		keys[action.String()] = b.keyNames(action)
+		buttons[action.String()] = b.buttonNames(action)
+	}
+	return keys, buttons
+}
+
+// keyNames returns the names of the keys bound to an action.
+func (b bindings) keyNames(a Action) []string {
+	names := []string{}
+	for _, k := range b.keys[a] {
+		names = append(names, k.String())
+	}
+	return names
+}
+
+// buttonNames returns the names of the gamepad buttons bound to an action.
+func (b bindings) buttonNames(a Action) []string {
+	names := []string{}
+	for _, button := range b.buttons[a] {
+		names = append(names, gamepadButtonNames[button])
+	}
+	return names
+}
+
+// hint labels the first key bound to each action for the prompt of a scene,
+// e.g. "ArrowUp/ArrowDown: Select", or is "" if an action has no key.
+func (b bindings) hint(label string, actions ...Action) string {
+	names := make([]string, len(actions))
+	for i, a := range actions {
+		if len(b.keys[a]) == 0 {
+			return ""
+		}
+		names[i] = b.keys[a][0].String()
+	}
+	return strings.Join(names, "/") + ": " + label
+}
+
+// joinHints lays out the non-empty hints of a prompt on one line.
+func joinHints(hints ...string) string {
+	return strings.Join(slices.DeleteFunc(hints, func(h string) bool { return h == "" }), "    ")
+}
+
+// clone returns a deep copy, so edits can be discarded.
+func (b bindings) clone() bindings {
+	out := bindings{keys: map[Action][]keyBinding{}, buttons: map[Action][]ebiten.StandardGamepadButton{}}
+	for a, keys := range b.keys {
+		out.keys[a] = slices.Clone(keys)
+	}
+	for a, buttons := range b.buttons {
+		out.buttons[a] = slices.Clone(buttons)
+	}
+	return out
+}
+
+// conflicts describes every input bound to two actions that are read in
+// the same scene, where only one of them could ever be meant.
+func (b bindings) conflicts() []string {
+	var out []string
+	for a1 := range actionCount {
+		for a2 := a1 + 1; a2 < actionCount; a2++ {
+			if actionContexts[a1]&actionContexts[a2] == 0 {
+				continue
+			}
+			for _, k := range b.keys[a1] {
+				if slices.Contains(b.keys[a2], k) {
+					out = append(out, fmt.Sprintf("%s is bound to both %s and %s", k, a1, a2))
+				}
+			}
+			for _, button := range b.buttons[a1] {
+				if slices.Contains(b.buttons[a2], button) {
+					out = append(out, fmt.Sprintf("%s is bound to both %s and %s", gamepadButtonNames[button], a1, a2))
+				}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"image/color"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	textv2 "github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// controlsScreen is the state of the rebinding screen.
+type controlsScreen struct {
+	edit      bindings // bindings being edited, applied when leaving
+	row       Action   // selected action
+	gamepad   bool     // the gamepad column is selected rather than the keyboard one
+	capturing bool     // waiting for the input to bind
+}
+
+// openControls switches to the rebinding screen.
+func (a *App) openControls() {
+	a.controls = &controlsScreen{edit: a.input.bindings.clone()}
+	a.scene = SceneControls
+}
+
+// modifierKeys are never bound on their own, they are read as part of a key binding.
+var modifierKeys = []ebiten.Key{
+	ebiten.KeyControl, ebiten.KeyControlLeft, ebiten.KeyControlRight,
+	ebiten.KeyShift, ebiten.KeyShiftLeft, ebiten.KeyShiftRight,
+	ebiten.KeyMeta, ebiten.KeyMetaLeft, ebiten.KeyMetaRight,
+	ebiten.KeyAlt, ebiten.KeyAltLeft, ebiten.KeyAltRight,
+}
+
+// padJustPressed reports whether a button was just pressed on any gamepad.
+func padJustPressed(button ebiten.StandardGamepadButton) bool {
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if inpututil.IsStandardGamepadButtonJustPressed(id, button) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateControls handles the rebinding screen: Up/Down select an action,
+// Left/Right the keyboard or gamepad column, Enter binds the next input,
+// Backspace clears the selected bindings, D restores the defaults and
+// Esc saves and goes back to the settings.
+// NOTE: The screen uses fixed keys rather than actions, so that a bad
+// binding can never lock the player out of fixing it.
+func updateControls(a *App) {
+	c := a.controls
+	if c.capturing {
+		c.capture()
+		return
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp), padJustPressed(ebiten.StandardGamepadButtonLeftTop):
+		c.row = max(c.row-1, 0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown), padJustPressed(ebiten.StandardGamepadButtonLeftBottom):
+		c.row = min(c.row+1, actionCount-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft), padJustPressed(ebiten.StandardGamepadButtonLeftLeft):
+		c.gamepad = false
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowRight), padJustPressed(ebiten.StandardGamepadButtonLeftRight):
+		c.gamepad = true
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter), padJustPressed(ebiten.StandardGamepadButtonRightBottom):
+		c.capturing = true
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace), inpututil.IsKeyJustPressed(ebiten.KeyDelete):
+		if c.gamepad {
+			c.edit.buttons[c.row] = nil
+		} else {
+			c.edit.keys[c.row] = nil
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyD):
+		c.edit = defaultBindings()
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape), padJustPressed(ebiten.StandardGamepadButtonRightRight):
+		a.applyControls()
+		a.controls = nil
+		a.scene = SceneSettings
+	}
+}
+
+// capture binds the next key or gamepad button to the selected action.
+// Escape cancels.
+func (c *controlsScreen) capture() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		c.capturing = false
+		return
+	}
+
+	if c.gamepad {
+		for _, id := range ebiten.AppendGamepadIDs(nil) {
+			for _, b := range inpututil.AppendJustPressedStandardGamepadButtons(id, nil) {
+				if !slices.Contains(c.edit.buttons[c.row], b) {
+					c.edit.buttons[c.row] = append(c.edit.buttons[c.row], b)
+				}
+				c.capturing = false
+				return
+			}
+		}
+		return
+	}
+
+	for _, key := range inpututil.AppendJustPressedKeys(nil) {
+		if slices.Contains(modifierKeys, key) {
+			continue
+		}
+		k := keyBinding{
+			Key:   key,
+			Ctrl:  ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta),
+			Shift: ebiten.IsKeyPressed(ebiten.KeyShift),
+		}
+		if !slices.Contains(c.edit.keys[c.row], k) {
+			c.edit.keys[c.row] = append(c.edit.keys[c.row], k)
+		}
+		c.capturing = false
+		return
+	}
+}
+
+// applyControls makes the edited bindings current and saves them.
+func (a *App) applyControls() {
+	a.prefs.Keys, a.prefs.Gamepad = a.controls.edit.export()
+	a.applyPrefs()
+	a.savePrefs()
+}
+
+// drawControls renders the table of bindings, then either the conflicts
+// or the help line.
+func drawControls(screen *ebiten.Image, c *controlsScreen) {
+	screen.Fill(currentTheme.Background)
+
+	// The table is laid out in design pixels, centered on the screen
+	left := view.centerX(view.px(designSize))
+	drawText := func(s string, x, y float64, col color.Color) {
+		opts := &textv2.DrawOptions{}
+		opts.GeoM.Translate(left+view.px(x), view.px(y))
+		opts.ColorScale.ScaleWithColor(col)
+		textv2.Draw(screen, s, MediumFace, opts)
+	}
+	white := currentTheme.Text
+	dim := currentTheme.Widget
+	highlight := currentTheme.Accent
+
+	title := "Controls"
+	tw, _ := textv2.Measure(title, LargeFace, 0)
+	tOpts := &textv2.DrawOptions{}
+	tOpts.ColorScale.ScaleWithColor(white)
+	tOpts.GeoM.Translate(view.centerX(tw), view.px(20))
+	textv2.Draw(screen, title, LargeFace, tOpts)
+
+	// Columns: action, keyboard, gamepad
+	const nameX, keysX, padX = 40.0, 220.0, 560.0
+	const top, rowHeight = 90.0, 27.0
+	drawText("ACTION", nameX, top, dim)
+	drawText("KEYBOARD", keysX, top, dim)
+	drawText("GAMEPAD", padX, top, dim)
+
+	for action := range actionCount {
+		y := top + rowHeight*float64(action+1)
+		selected := action == c.row
+
+		name := action.String()
+		if selected {
+			name = "> " + name
+		}
+		drawText(name, nameX, y, white)
+
+		keys := strings.Join(c.edit.keyNames(action), ", ")
+		buttons := strings.Join(c.edit.buttonNames(action), ", ")
+		keysCol, padCol := color.Color(white), color.Color(white)
+		if selected {
+			if c.gamepad {
+				padCol = highlight
+			} else {
+				keysCol = highlight
+			}
+			if c.capturing && c.gamepad {
+				buttons = "press a button..."
+			} else if c.capturing {
+				keys = "press a key..."
+			}
+		}
+		drawText(keys, keysX, y, keysCol)
+		drawText(buttons, padX, y, padCol)
+	}
+
+	y := top + rowHeight*float64(actionCount+2)
+	conflicts := c.edit.conflicts()
+	if len(conflicts) == 0 {
+		drawText("Enter: Bind  Backspace: Clear  D: Defaults  Esc: Save", nameX, y, dim)
+		return
+	}
+	for _, msg := range conflicts[:min(len(conflicts), 3)] {
+		drawText("Conflict: "+msg, nameX, y, highlight)
+		y += rowHeight
+	}
+}
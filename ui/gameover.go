@@ -12,51 +12,84 @@ import (
 )
 
 func updateGameOver(a *App) {
-	if ebiten.IsKeyPressed(ebiten.KeyR) {
+	if a.input.justPressed(ActionRetry) {
 		// Reset the game engine and switch to play scene
-		a.engine = engine.NewGame()
+		a.newGame()
 		a.scene = ScenePlay
 	}
 
-	if ebiten.IsKeyPressed(ebiten.KeyM) {
+	if a.input.justPressed(ActionMenu) {
 		// Reset the game engine and switch to menu scene
 		a.scene = SceneMenu
 	}
+
+	if a.input.justPressed(ActionUndo) && a.canUndoGameOver() && a.engine.Undo() {
+		// Take back the last move and keep playing
+		a.recordUndo()
+		a.scene = ScenePlay
+	}
+
+	if a.input.justPressed(ActionWatch) && a.replay != nil {
+		// Watch the game that just ended
+		a.watchReplay(a.replay)
+	}
 }
 
-// drawGameOver overlays a semi-transparent backdrop and centered messages.
-func drawGameOver(screen *ebiten.Image, score int) {
+// canUndoGameOver reports whether the last move of the finished game can be
+// taken back. Undo gives a move back to a spent budget, but not time.
+func (a *App) canUndoGameOver() bool {
+	reason, _ := a.engine.Over()
+	return a.engine.UndoCount() > 0 && reason != engine.EndTimeUp
+}
+
+// gameOverTitles are the titles of the game over overlay, by reason.
+var gameOverTitles = map[engine.EndReason]string{
+	engine.EndStuck:   "Game Over",
+	engine.EndTimeUp:  "Time's Up!",
+	engine.EndNoMoves: "Out of Moves!",
+}
+
+// drawGameOver overlays a semi-transparent backdrop and centered messages,
+// titled with the reason the game ended.
+func drawGameOver(screen *ebiten.Image, keys bindings, score int, reason engine.EndReason, canUndo, canWatch bool) {
 	// Dark overlay
 	overlayCol := color.RGBA{0, 0, 0, 180} // ~70% opacity
 	vector.DrawFilledRect(screen,
 		0, 0,
-		float32(engine.ScreenWidth), float32(engine.ScreenHeight),
+		float32(view.width), float32(view.height),
 		overlayCol, false)
 
-	// "Game Over" title
-	title := "Game Over"
+	// "Game Over" title, or why the challenge ended
+	title := gameOverTitles[reason]
 	tw, th := textv2.Measure(title, LargeFace, 0)
-	tx := (engine.ScreenWidth - int(tw)) / 2
-	ty := engine.ScreenHeight / 3
+	tx := view.centerX(tw)
+	ty := view.height / 3
 	topts := &textv2.DrawOptions{}
-	topts.GeoM.Translate(float64(tx), float64(ty))
+	topts.GeoM.Translate(tx, ty)
 	textv2.Draw(screen, title, LargeFace, topts)
 
 	// Show final score
 	scoreMsg := fmt.Sprintf("Score: %d", score)
 	sw, sh := textv2.Measure(scoreMsg, MediumFace, 0)
-	sx := (engine.ScreenWidth - int(sw)) / 2
-	sy := ty + int(th) + 20
+	sx := view.centerX(sw)
+	sy := ty + th + view.px(20)
 	sopts := &textv2.DrawOptions{}
-	sopts.GeoM.Translate(float64(sx), float64(sy))
+	sopts.GeoM.Translate(sx, sy)
 	textv2.Draw(screen, scoreMsg, MediumFace, sopts)
 
 	// Instructions
-	info := "R: Retry    M: Menu"
+	hints := []string{keys.hint("Retry", ActionRetry), keys.hint("Menu", ActionMenu)}
+	if canUndo {
+		hints = append(hints, keys.hint("Undo", ActionUndo))
+	}
+	if canWatch {
+		hints = append(hints, keys.hint("Watch", ActionWatch))
+	}
+	info := joinHints(hints...)
 	iw, _ := textv2.Measure(info, MediumFace, 0)
-	ix := (engine.ScreenWidth - int(iw)) / 2
-	iy := sy + int(sh) + 30
+	ix := view.centerX(iw)
+	iy := sy + sh + view.px(30)
 	iopts := &textv2.DrawOptions{}
-	iopts.GeoM.Translate(float64(ix), float64(iy))
+	iopts.GeoM.Translate(ix, iy)
 	textv2.Draw(screen, info, MediumFace, iopts)
 }
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"math"
+
+	"2048/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Thresholds for recognizing gestures, in design pixels (see screenLayout)
+// and seconds.
+const (
+	swipeMinDistance = 50.0  // a slow drag must be at least this long to count as a swipe
+	flickMinDistance = 20.0  // a fast flick can be shorter...
+	flickMinSpeed    = 600.0 // ...if it moves at least this many pixels per second
+	swipeAxisRatio   = 1.5   // the dominant axis must be this much longer than the other
+	tapMaxDistance   = 10.0  // a press that moves less than this is a tap
+)
+
+// gestureKind tells what a finished press turned out to be.
+type gestureKind int
+
+const (
+	gestureNone gestureKind = iota
+	gestureSwipe
+	gestureTap
+)
+
+// gesture is the outcome of a press released this update.
+type gesture struct {
+	kind gestureKind
+	dir  engine.Direction // gestureSwipe only
+	x, y int              // where it started
+}
+
+// pointerTracker follows one mouse drag or touch at a time,
+// from the moment it is pressed until it is released.
+type pointerTracker struct {
+	active bool
+	touch  bool           // following a touch rather than the mouse
+	id     ebiten.TouchID // touch followed, if touch
+	startX int
+	startY int
+	ticks  int // updates since the press
+}
+
+// update must be called once per update and returns the gesture completed
+// by a release, if any. Extra fingers are ignored while one is followed.
+func (t *pointerTracker) update() gesture {
+	if !t.active {
+		t.start()
+		return gesture{}
+	}
+	t.ticks++
+
+	var x, y int
+	switch {
+	case t.touch && inpututil.IsTouchJustReleased(t.id):
+		x, y = inpututil.TouchPositionInPreviousTick(t.id)
+	case !t.touch && inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft):
+		x, y = ebiten.CursorPosition()
+	default:
+		return gesture{} // still pressed
+	}
+	t.active = false
+	return classify(t.startX, t.startY, x, y, t.ticks)
+}
+
+// start begins following a new press, if there is one.
+func (t *pointerTracker) start() {
+	if ids := inpututil.AppendJustPressedTouchIDs(nil); len(ids) > 0 {
+		x, y := ebiten.TouchPosition(ids[0])
+		*t = pointerTracker{active: true, touch: true, id: ids[0], startX: x, startY: y}
+		return
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := ebiten.CursorPosition()
+		*t = pointerTracker{active: true, startX: x, startY: y}
+	}
+}
+
+// classify turns a press from (x0, y0) released at (x1, y1) after the given
+// number of updates into a swipe, a tap, or nothing.
+func classify(x0, y0, x1, y1, ticks int) gesture {
+	g := gesture{x: x0, y: y0}
+	dx, dy := float64(x1-x0), float64(y1-y0)
+	distance := math.Hypot(dx, dy) / view.unit
+	if distance < tapMaxDistance {
+		g.kind = gestureTap
+		return g
+	}
+
+	seconds := float64(max(ticks, 1)) / float64(ebiten.TPS())
+	long := distance >= swipeMinDistance
+	fast := distance >= flickMinDistance && distance/seconds >= flickMinSpeed
+	if !long && !fast {
+		return g
+	}
+
+	// Diagonal strokes are ambiguous, ignore them
+	ax, ay := math.Abs(dx), math.Abs(dy)
+	switch {
+	case ax >= ay*swipeAxisRatio:
+		g.kind, g.dir = gestureSwipe, engine.Right
+		if dx < 0 {
+			g.dir = engine.Left
+		}
+	case ay >= ax*swipeAxisRatio:
+		g.kind, g.dir = gestureSwipe, engine.Down
+		if dy < 0 {
+			g.dir = engine.Up
+		}
+	}
+	return g
+}
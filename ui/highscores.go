@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"2048/settings"
+	"2048/storage"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	textv2 "github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+func updateHighScores(a *App) {
+	// Left/Right switch between the tables of the challenges
+	a.scoresIndex = a.input.cycleOption(a.scoresIndex, len(settings.Challenges))
+
+	// Any of the usual "back" keys returns to the menu
+	if a.input.justPressed(ActionBack) ||
+		a.input.justPressed(ActionConfirm) ||
+		a.input.justPressed(ActionMenu) {
+		a.scene = SceneMenu
+	}
+}
+
+// drawHighScores renders the high-score table of the challenge at index
+// challenge of settings.Challenges.
+func drawHighScores(screen *ebiten.Image, keys bindings, scores *storage.Scores, challenge int) {
+	// Clear the background
+	screen.Fill(currentTheme.Background)
+
+	// Title
+	title := "High Scores"
+	tw, th := textv2.Measure(title, LargeFace, 0)
+	tx := view.centerX(tw)
+	ty := view.px(80)
+	topts := &textv2.DrawOptions{}
+	topts.ColorScale.ScaleWithColor(currentTheme.Text)
+	topts.GeoM.Translate(tx, ty)
+	textv2.Draw(screen, title, LargeFace, topts)
+
+	// Name of the table, the regular one being "None"
+	name := settings.Challenges[challenge]
+	table := scores.Challenge(name)
+	sub := fmt.Sprintf("< %s >", challengeNames[name])
+	if name == settings.ChallengeNone {
+		sub = "< Classic >"
+	}
+	sw, sh := textv2.Measure(sub, MediumFace, 0)
+	sopts := &textv2.DrawOptions{}
+	sopts.ColorScale.ScaleWithColor(currentTheme.Text)
+	sopts.GeoM.Translate(view.centerX(sw), ty+th+view.px(12))
+	textv2.Draw(screen, sub, MediumFace, sopts)
+
+	// NOTE: The font is monospaced, so fixed-width columns line up.
+	rows := []string{fmt.Sprintf("%2s  %7s  %6s  %5s  %8s  %5s  %s",
+		"#", "SCORE", "TILE", "MOVES", "TIME", "SIZE", "DATE")}
+	for i, e := range table.Entries {
+		rows = append(rows, fmt.Sprintf("%2d  %7d  %6d  %5d  %8s  %5s  %s",
+			i+1, e.Score, e.MaxTile, e.Moves, formatDuration(e.Duration),
+			BoardSize{e.Rows, e.Columns}, e.Date.Format("2006-01-02")))
+	}
+	if len(table.Entries) == 0 {
+		rows = append(rows, "", "No games finished yet")
+	}
+
+	// Left-align every row on the header's position
+	hw, hh := textv2.Measure(rows[0], MediumFace, 0)
+	rx := view.centerX(hw)
+	ry := ty + th + sh + view.px(40)
+	for _, row := range rows {
+		ropts := &textv2.DrawOptions{}
+		ropts.ColorScale.ScaleWithColor(currentTheme.Text)
+		ropts.GeoM.Translate(rx, ry)
+		textv2.Draw(screen, row, MediumFace, ropts)
+		ry += hh + view.px(12)
+	}
+
+	// Instructions
+	info := joinHints(keys.hint("Challenge", ActionLeft, ActionRight), keys.hint("Back", ActionBack))
+	iw, _ := textv2.Measure(info, MediumFace, 0)
+	ix := view.centerX(iw)
+	iy := ry + view.px(30)
+	iopts := &textv2.DrawOptions{}
+	iopts.ColorScale.ScaleWithColor(currentTheme.Text)
+	iopts.GeoM.Translate(ix, iy)
+	textv2.Draw(screen, info, MediumFace, iopts)
+}
+
+// formatDuration renders a play time as m:ss, or h:mm:ss for long games.
+func formatDuration(d time.Duration) string {
+	total := int(d.Round(time.Second) / time.Second)
+	h, m, s := total/3600, total/60%60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
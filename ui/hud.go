@@ -3,7 +3,6 @@ package ui
 
 import (
 	"fmt"
-	"image/color"
 
 	"2048/engine"
 
@@ -17,84 +16,187 @@ const (
 	HUDHeight = 90
 )
 
-// drawHUD draws the heads-up display (HUD) at the top of the screen.
-func drawHUD(screen *ebiten.Image, score, best int) {
+// Layout of the HUD boxes, in design pixels (see screenLayout)
+const (
+	widgetWidth   = 120
+	widgetHeight  = 60
+	widgetPadding = 20
+	widgetY       = (HUDHeight - widgetHeight) / 2 // Center vertically in HUD
+	valuePadding  = 6                              // space left of and right of long scores
+)
+
+// hudWidget identifies a box of the HUD.
+type hudWidget int
+
+const (
+	hudScore hudWidget = iota
+	hudBest
+	hudUndo
+	hudNext // preview of the next tile, only for rules that have one
+	hudMenu // right-aligned, or at the bottom in landscape
+)
+
+// rect returns the area of the widget on the screen. The HUD is a row of
+// boxes above the board, or a column of them in landscape.
+func (w hudWidget) rect() rect {
+	width, height, pad := view.px(widgetWidth), view.px(widgetHeight), view.px(widgetPadding)
+	if view.landscape {
+		y := pad + float64(w)*(height+pad)
+		if w == hudMenu {
+			y = view.hud.h - pad - height
+		}
+		return rect{pad, y, width, height}
+	}
+
+	x := pad + float64(w)*(width+pad)
+	if w == hudMenu {
+		x = view.width - width - pad
+	}
+	return rect{x, view.px(widgetY), width, height}
+}
+
+// statusRect returns the area of the HUD status text: the gap between the
+// last box of the row (Undo, or Next with a preview) and the Menu box, or
+// below that box in landscape.
+func statusRect(preview bool) rect {
+	last, menu := hudUndo.rect(), hudMenu.rect()
+	if preview {
+		last = hudNext.rect()
+	}
+	pad := view.px(widgetPadding)
+	if view.landscape {
+		return rect{last.x, last.y + last.h + pad, last.w, last.h}
+	}
+	x := last.x + last.w + pad
+	return rect{x, last.y, menu.x - pad - x, last.h}
+}
+
+// hudWidgetAt returns the widget under a screen position, so that the HUD
+// can be clicked or tapped.
+func hudWidgetAt(x, y int) (hudWidget, bool) {
+	for _, w := range []hudWidget{hudScore, hudBest, hudUndo, hudMenu} {
+		if w.rect().contains(x, y) {
+			return w, true
+		}
+	}
+	return 0, false
+}
+
+// tapped reports whether a HUD widget was clicked or tapped this update.
+func (a *App) tapped(w hudWidget) bool {
+	if a.gesture.kind != gestureTap {
+		return false
+	}
+	hit, ok := hudWidgetAt(a.gesture.x, a.gesture.y)
+	return ok && hit == w
+}
+
+// drawHUD draws the heads-up display (HUD) of a game at the top of the
+// screen, or on its left in landscape.
+// best is -1 for games that can't enter a high-score table, leaving out
+// the BEST box. status is a short note (e.g. the autoplay rate) shown next
+// to the widgets.
+func drawHUD(screen *ebiten.Image, keys bindings, g *engine.Game, best, undos int, status string) {
 	// Background bar
-	barBg := color.RGBA{187, 173, 160, 255}
+	hud := view.hud
 	vector.DrawFilledRect(screen,
-		0, 0,
-		float32(engine.ScreenWidth), float32(HUDHeight),
-		barBg, false)
-
-	// Define layout for the score widgets
-	const widgetWidth = 120
-	const widgetPadding = 20
-
-	// Draw Score, Best, and Menu widgets
-	drawScoreWidget(screen, "SCORE", score, widgetPadding)
-	drawScoreWidget(screen, "BEST", best, widgetPadding*2+widgetWidth)
-	drawMenuWidget(screen, "MENU (M)", engine.ScreenWidth-widgetWidth-widgetPadding)
+		float32(hud.x), float32(hud.y),
+		float32(hud.w), float32(hud.h),
+		currentTheme.Background, false)
+
+	// Draw Score, Best, Undo, and Menu widgets
+	drawScoreWidget(screen, "SCORE", g.Score, hudScore.rect())
+	if best >= 0 {
+		drawScoreWidget(screen, "BEST", best, hudBest.rect())
+	}
+	drawScoreWidget(screen, "UNDO", undos, hudUndo.rect())
+	preview := g.Next != 0
+	if preview {
+		drawNextWidget(screen, g.Rules, g.Next, hudNext.rect())
+	}
+	menu := "MENU"
+	if k := keys.keys[ActionMenu]; len(k) > 0 {
+		menu += " (" + k[0].String() + ")"
+	}
+	drawMenuWidget(screen, menu, hudMenu.rect())
+
+	// Status text, centered in its area and shrunk if it doesn't fit
+	if status != "" {
+		r := statusRect(preview)
+		sw, sh := textv2.Measure(status, MediumFace, 0)
+		fit := fitScale(sw, sh, r.w, r.h)
+		opts := &textv2.DrawOptions{}
+		opts.GeoM.Scale(fit, fit)
+		opts.GeoM.Translate(r.x+(r.w-sw*fit)/2, r.y+(r.h-sh*fit)/2)
+		opts.ColorScale.ScaleWithColor(currentTheme.Text)
+		opts.Filter = ebiten.FilterLinear
+		textv2.Draw(screen, status, MediumFace, opts)
+	}
 }
 
-// drawScoreWidget draws a single box with a title and a right-aligned value.
-func drawScoreWidget(screen *ebiten.Image, title string, value int, xPos float64) {
+// drawScoreWidget draws a single box with a title above a centered value.
+func drawScoreWidget(screen *ebiten.Image, title string, value int, r rect) {
 	// Widget background
-	widgetBg := color.RGBA{143, 122, 102, 255}
-	const widgetHeight = 60
-	const widgetWidth = 120
-	widgetY := (HUDHeight - widgetHeight) / 2 // Center vertically in HUD
-	vector.DrawFilledRect(screen, float32(xPos), float32(widgetY), widgetWidth, widgetHeight, widgetBg, false)
+	vector.DrawFilledRect(screen, float32(r.x), float32(r.y), float32(r.w), float32(r.h), currentTheme.Widget, false)
 
 	// Draw Title (e.g., "SCORE")
-	titleColor := color.RGBA{238, 228, 218, 255}
-	titleBoundsX, _ := textv2.Measure(title, MediumFace, 0)
-	titleX := xPos + (widgetWidth-titleBoundsX)/2
-	titleY := float64(widgetY + 25) // Position in top half
+	titleBoundsX, titleBoundsY := textv2.Measure(title, MediumFace, 0)
+	titleX := r.x + (r.w-titleBoundsX)/2
+	titleY := r.y + view.px(2) // Position in top half
 
 	opts := &textv2.DrawOptions{}
 	opts.GeoM.Translate(titleX, titleY)
-	opts.ColorScale.SetR(float32(titleColor.R / 255.0))
-	opts.ColorScale.SetG(float32(titleColor.G / 255.0))
-	opts.ColorScale.SetB(float32(titleColor.B / 255.0))
-	opts.ColorScale.SetA(float32(titleColor.A / 255.0))
+	opts.ColorScale.ScaleWithColor(currentTheme.WidgetText)
 	textv2.Draw(screen, title, MediumFace, opts)
 
-	// Draw Value (e.g., "4096")
+	// Draw Value (e.g., "4096"), shrunk to fit the rest of the box
 	valueStr := fmt.Sprintf("%d", value)
-	_, valueBoundsY := textv2.Measure(valueStr, LargeFace, 0)
-	valueX := xPos + (widgetWidth-valueBoundsY)/2
-	valueY := float64(widgetY + 58) // Position in bottom half
+	valueBoundsX, valueBoundsY := textv2.Measure(valueStr, LargeFace, 0)
+	fit := fitScale(valueBoundsX, valueBoundsY, r.w-2*view.px(valuePadding), r.h-titleBoundsY-view.px(2))
+	valueX := r.x + (r.w-valueBoundsX*fit)/2
+	valueY := r.y + r.h - valueBoundsY*fit // Position in bottom half
 
 	opts.GeoM.Reset()
+	opts.GeoM.Scale(fit, fit)
 	opts.GeoM.Translate(valueX, valueY)
-	opts.ColorScale.SetR(255) // White text for values
-	opts.ColorScale.SetG(255)
-	opts.ColorScale.SetB(255)
-	opts.ColorScale.SetA(255)
+	opts.Filter = ebiten.FilterLinear
+	opts.ColorScale.Reset()
+	opts.ColorScale.ScaleWithColor(currentTheme.Text)
 	textv2.Draw(screen, valueStr, LargeFace, opts)
 }
 
+// drawNextWidget draws a box with a title above the tile that spawns next.
+func drawNextWidget(screen *ebiten.Image, rules engine.Rules, next int, r rect) {
+	vector.DrawFilledRect(screen, float32(r.x), float32(r.y), float32(r.w), float32(r.h), currentTheme.Widget, false)
+
+	title := "NEXT"
+	titleBoundsX, titleBoundsY := textv2.Measure(title, MediumFace, 0)
+	opts := &textv2.DrawOptions{}
+	opts.GeoM.Translate(r.x+(r.w-titleBoundsX)/2, r.y+view.px(2))
+	opts.ColorScale.ScaleWithColor(currentTheme.WidgetText)
+	textv2.Draw(screen, title, MediumFace, opts)
+
+	// The tile fills the rest of the box, below the title
+	top := r.y + titleBoundsY + view.px(2)
+	size := r.y + r.h - top - view.px(valuePadding)
+	drawTile(screen, rules, r.x+r.w/2, top+size/2, size, next, 1)
+}
+
 // drawMenuWidget is a simpler widget for the menu button.
-func drawMenuWidget(screen *ebiten.Image, text string, xPos float64) {
+func drawMenuWidget(screen *ebiten.Image, text string, r rect) {
 	// Widget background (same as score)
-	widgetBg := color.RGBA{143, 122, 102, 255}
-	const widgetHeight = 60
-	const widgetWidth = 120
-	widgetY := (HUDHeight - widgetHeight) / 2
-	vector.DrawFilledRect(screen, float32(xPos), float32(widgetY), widgetWidth, widgetHeight, widgetBg, false)
-
-	// Draw Text
-	textColor := color.RGBA{238, 228, 218, 255}
+	vector.DrawFilledRect(screen, float32(r.x), float32(r.y), float32(r.w), float32(r.h), currentTheme.Widget, false)
+
+	// Draw Text, shrunk if a long key name doesn't fit
 	boundsX, boundsY := textv2.Measure(text, MediumFace, 0)
-	textX := xPos + (widgetWidth-boundsX)/2
-	textY := float64(widgetY) + (widgetHeight+boundsY)/2 // Vertically center text in the box
+	fit := fitScale(boundsX, boundsY, r.w-view.px(8), r.h)
+	textX := r.x + (r.w-boundsX*fit)/2
+	textY := r.y + (r.h-boundsY*fit)/2 // Vertically center text in the box
 
 	opts := &textv2.DrawOptions{}
+	opts.GeoM.Scale(fit, fit)
 	opts.GeoM.Translate(textX, textY)
-	opts.ColorScale.SetR(float32(textColor.R / 255.0))
-	opts.ColorScale.SetG(float32(textColor.G / 255.0))
-	opts.ColorScale.SetB(float32(textColor.B / 255.0))
-	opts.ColorScale.SetA(float32(textColor.A / 255.0))
+	opts.Filter = ebiten.FilterLinear
+	opts.ColorScale.ScaleWithColor(currentTheme.WidgetText)
 	textv2.Draw(screen, text, MediumFace, opts)
 }
-
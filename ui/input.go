@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"log"
+	"math"
+
+	"2048/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Action is something the player asks for, whatever the device used.
+type Action int
+
+const (
+	ActionLeft Action = iota
+	ActionUp
+	ActionRight
+	ActionDown
+	ActionConfirm     // pick the selected entry, play/pause a replay
+	ActionBack        // leave a screen
+	ActionMenu        // save and go back to the menu
+	ActionRetry       // start over once the game is lost
+	ActionUndo        // take back the last turn
+	ActionRedo        // re-apply an undone turn
+	ActionHint        // ask the solver for a move
+	ActionAutoplay    // let the solver play
+	ActionSlower      // slow down autoplay or a replay
+	ActionFaster      // speed up autoplay or a replay
+	ActionWatch       // watch the replay of the game that just ended
+	ActionSeekBack    // jump back in a replay
+	ActionSeekForward // jump forward in a replay
+	ActionSeekStart   // go to the start of a replay
+	ActionSeekEnd     // go to the end of a replay
+	ActionFullscreen  // switch between the window and fullscreen
+	actionCount
+)
+
+// keyBinding is a key, possibly combined with modifiers.
+// Modifiers must match exactly, so that Ctrl+Z and Ctrl+Shift+Z differ.
+type keyBinding struct {
+	Key   ebiten.Key
+	Ctrl  bool // Control, or Command on macOS
+	Shift bool
+}
+
+// bindings maps each action to the inputs triggering it.
+type bindings struct {
+	keys    map[Action][]keyBinding
+	buttons map[Action][]ebiten.StandardGamepadButton
+}
+
+// defaultBindings returns the built-in keyboard and gamepad bindings.
+func defaultBindings() bindings {
+	return bindings{
+		keys: map[Action][]keyBinding{
+			// Arrows, WASD and vim keys
+			ActionLeft:        {{Key: ebiten.KeyArrowLeft}, {Key: ebiten.KeyA}, {Key: ebiten.KeyH}},
+			ActionUp:          {{Key: ebiten.KeyArrowUp}, {Key: ebiten.KeyW}, {Key: ebiten.KeyK}},
+			ActionRight:       {{Key: ebiten.KeyArrowRight}, {Key: ebiten.KeyD}, {Key: ebiten.KeyL}},
+			ActionDown:        {{Key: ebiten.KeyArrowDown}, {Key: ebiten.KeyS}, {Key: ebiten.KeyJ}},
+			ActionConfirm:     {{Key: ebiten.KeyEnter}, {Key: ebiten.KeySpace}},
+			ActionBack:        {{Key: ebiten.KeyEscape}},
+			ActionMenu:        {{Key: ebiten.KeyM}},
+			ActionRetry:       {{Key: ebiten.KeyR}},
+			ActionUndo:        {{Key: ebiten.KeyU}, {Key: ebiten.KeyZ, Ctrl: true}},
+			ActionRedo:        {{Key: ebiten.KeyY, Ctrl: true}, {Key: ebiten.KeyZ, Ctrl: true, Shift: true}},
+			ActionHint:        {{Key: ebiten.KeySlash}}, // the "?" key
+			ActionAutoplay:    {{Key: ebiten.KeyP}},
+			ActionSlower:      {{Key: ebiten.KeyBracketLeft}},
+			ActionFaster:      {{Key: ebiten.KeyBracketRight}},
+			ActionWatch:       {{Key: ebiten.KeyW}},
+			ActionSeekBack:    {{Key: ebiten.KeyPageUp}},
+			ActionSeekForward: {{Key: ebiten.KeyPageDown}},
+			ActionSeekStart:   {{Key: ebiten.KeyHome}},
+			ActionSeekEnd:     {{Key: ebiten.KeyEnd}},
+			ActionFullscreen:  {{Key: ebiten.KeyF11}},
+		},
+		buttons: map[Action][]ebiten.StandardGamepadButton{
+			ActionLeft:        {ebiten.StandardGamepadButtonLeftLeft},
+			ActionUp:          {ebiten.StandardGamepadButtonLeftTop},
+			ActionRight:       {ebiten.StandardGamepadButtonLeftRight},
+			ActionDown:        {ebiten.StandardGamepadButtonLeftBottom},
+			ActionConfirm:     {ebiten.StandardGamepadButtonRightBottom}, // A
+			ActionBack:        {ebiten.StandardGamepadButtonRightRight},  // B
+			ActionUndo:        {ebiten.StandardGamepadButtonRightLeft},   // X
+			ActionRetry:       {ebiten.StandardGamepadButtonRightTop},    // Y
+			ActionMenu:        {ebiten.StandardGamepadButtonCenterRight}, // Start
+			ActionHint:        {ebiten.StandardGamepadButtonCenterLeft},  // Select
+			ActionWatch:       {ebiten.StandardGamepadButtonCenterLeft},
+			ActionAutoplay:    {ebiten.StandardGamepadButtonLeftStick},
+			ActionSlower:      {ebiten.StandardGamepadButtonFrontTopLeft},
+			ActionFaster:      {ebiten.StandardGamepadButtonFrontTopRight},
+			ActionRedo:        {ebiten.StandardGamepadButtonFrontBottomRight},
+			ActionSeekBack:    {ebiten.StandardGamepadButtonFrontBottomLeft},
+			ActionSeekForward: {ebiten.StandardGamepadButtonFrontBottomRight},
+		},
+	}
+}
+
+// The left stick counts as a D-pad press once pushed past stickPress along
+// one axis, and must come back under stickRelease before it triggers again.
+const (
+	stickPress   = 0.5
+	stickRelease = 0.3
+)
+
+// input turns keyboard and gamepad state into actions, once per update.
+type input struct {
+	bindings bindings
+	gamepads []ebiten.GamepadID
+	sticks   map[ebiten.GamepadID]bool // left stick held away from the center
+	pressed  [actionCount]bool         // actions triggered this update
+}
+
+func newInput() *input {
+	return &input{bindings: defaultBindings(), sticks: map[ebiten.GamepadID]bool{}}
+}
+
+// update reads the devices. It must be called once at the start of every update.
+func (in *input) update() {
+	in.pressed = [actionCount]bool{}
+
+	// Gamepads can be plugged and unplugged at any time
+	for _, id := range inpututil.AppendJustConnectedGamepadIDs(nil) {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			log.Printf("gamepad %q has no standard layout, it is ignored", ebiten.GamepadName(id))
+		}
+	}
+	for id := range in.sticks {
+		if inpututil.IsGamepadJustDisconnected(id) {
+			delete(in.sticks, id)
+		}
+	}
+	in.gamepads = ebiten.AppendGamepadIDs(in.gamepads[:0])
+
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)
+	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+	for action, keys := range in.bindings.keys {
+		for _, k := range keys {
+			if inpututil.IsKeyJustPressed(k.Key) && k.Ctrl == ctrl && k.Shift == shift {
+				in.pressed[action] = true
+			}
+		}
+	}
+
+	for _, id := range in.gamepads {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		for action, buttons := range in.bindings.buttons {
+			for _, b := range buttons {
+				if inpututil.IsStandardGamepadButtonJustPressed(id, b) {
+					in.pressed[action] = true
+				}
+			}
+		}
+		if dir, ok := in.updateStick(id); ok {
+			in.pressed[directionActions[dir]] = true
+		}
+	}
+}
+
+// updateStick returns the direction the left stick of a gamepad was just pushed in.
+func (in *input) updateStick(id ebiten.GamepadID) (engine.Direction, bool) {
+	x := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+	y := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+
+	if in.sticks[id] {
+		if math.Max(math.Abs(x), math.Abs(y)) < stickRelease {
+			in.sticks[id] = false
+		}
+		return 0, false
+	}
+
+	var dir engine.Direction
+	switch {
+	case math.Abs(x) >= stickPress && math.Abs(x) > math.Abs(y):
+		dir = engine.Right
+		if x < 0 {
+			dir = engine.Left
+		}
+	case math.Abs(y) >= stickPress:
+		dir = engine.Down
+		if y < 0 {
+			dir = engine.Up
+		}
+	default:
+		return 0, false
+	}
+	in.sticks[id] = true
+	return dir, true
+}
+
+// justPressed reports whether the action was triggered this update.
+func (in *input) justPressed(a Action) bool {
+	return in.pressed[a]
+}
+
+// directionActions maps each direction to its action.
+var directionActions = map[engine.Direction]Action{
+	engine.Left:  ActionLeft,
+	engine.Up:    ActionUp,
+	engine.Right: ActionRight,
+	engine.Down:  ActionDown,
+}
+
+// direction returns the direction triggered this update, if any.
+func (in *input) direction() (engine.Direction, bool) {
+	for _, dir := range engine.Directions {
+		if in.pressed[directionActions[dir]] {
+			return dir, true
+		}
+	}
+	return 0, false
+}
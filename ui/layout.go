@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"2048/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// designSize is the side of the square screen the sizes of the ui are given
+// for, in pixels. They are scaled by screenLayout.unit to the actual screen.
+const designSize = engine.ScreenWidth
+
+// landscapeRatio is how much wider than tall the screen must be for the HUD
+// to move from above the board to a column on its left.
+const landscapeRatio = 1.25
+
+// hudColumnWidth is the width of the HUD in landscape, in design pixels.
+const hudColumnWidth = widgetWidth + 2*widgetPadding
+
+// rect is an area of the screen, in pixels.
+type rect struct {
+	x, y, w, h float64
+}
+
+// contains reports whether a screen position is inside the area.
+func (r rect) contains(x, y int) bool {
+	return float64(x) >= r.x && float64(x) < r.x+r.w &&
+		float64(y) >= r.y && float64(y) < r.y+r.h
+}
+
+// screenLayout places the HUD and the board on a screen of any size.
+type screenLayout struct {
+	width, height float64 // size of the screen, in device pixels
+	unit          float64 // device pixels per design pixel
+	landscape     bool    // the HUD is a column left of the board
+	hud           rect
+	board         rect // area the board is centered in
+}
+
+// view is the layout of the screen, updated by App.LayoutF.
+var view = newScreenLayout(engine.ScreenWidth, engine.ScreenHeight)
+
+// newScreenLayout lays out a screen of the given size in device pixels.
+// NOTE: The unit follows the shorter side, so the board keeps the same share
+// of the screen whatever its aspect ratio.
+func newScreenLayout(width, height float64) screenLayout {
+	l := screenLayout{
+		width:     width,
+		height:    height,
+		unit:      min(width, height) / designSize,
+		landscape: width >= height*landscapeRatio,
+	}
+	if l.landscape {
+		l.hud = rect{0, 0, l.px(hudColumnWidth), height}
+		l.board = rect{l.hud.w, 0, width - l.hud.w, height}
+	} else {
+		l.hud = rect{0, 0, width, l.px(HUDHeight)}
+		l.board = rect{0, l.hud.h, width, height - l.hud.h}
+	}
+	return l
+}
+
+// px converts a size in design pixels to screen pixels.
+func (l screenLayout) px(v float64) float64 {
+	return v * l.unit
+}
+
+// centerX returns the left edge of something of the given width centered
+// on the screen.
+func (l screenLayout) centerX(width float64) float64 {
+	return (l.width - width) / 2
+}
+
+// LayoutF sizes the screen to the window in device pixels, so that text
+// stays sharp on high-DPI displays, and lays out the ui for it.
+func (a *App) LayoutF(outsideWidth, outsideHeight float64) (float64, float64) {
+	scale := ebiten.Monitor().DeviceScaleFactor()
+	width, height := outsideWidth*scale, outsideHeight*scale
+	if width != view.width || height != view.height {
+		view = newScreenLayout(width, height)
+		scaleFaces(view.unit)
+	}
+	return width, height
+}
+
+// Layout is required by ebiten.Game but never called, as App implements
+// ebiten.LayoutFer.
+func (a *App) Layout(outsideWidth, outsideHeight int) (int, int) {
+	w, h := a.LayoutF(float64(outsideWidth), float64(outsideHeight))
+	return int(w), int(h)
+}
+
+// toggleFullscreen switches between the window and fullscreen, and
+// remembers the choice for the next launch.
+func (a *App) toggleFullscreen() {
+	// NOTE: Read back from the window, which may have left fullscreen on its own
+	a.prefs.Fullscreen = !ebiten.IsFullscreen()
+	a.applyPrefs()
+	a.savePrefs()
+}
@@ -2,50 +2,241 @@ package ui
 
 import (
 	"fmt"
-	"image/color"
+	"slices"
+	"strings"
 
 	"2048/engine"
+	"2048/settings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	textv2 "github.com/hajimehoshi/ebiten/v2/text/v2"
 )
 
-func drawMenu(screen *ebiten.Image, bestScore int) {
+// BoardSize describes a selectable board shape.
+type BoardSize struct {
+	Rows    int
+	Columns int
+}
+
+func (s BoardSize) String() string {
+	return fmt.Sprintf("%dx%d", s.Columns, s.Rows)
+}
+
+// BoardSizes lists the board shapes offered in the menu, from smallest to largest.
+var BoardSizes = []BoardSize{
+	{3, 3},
+	{engine.DefaultGridN, engine.DefaultGridN},
+	{5, 5},
+	{6, 6},
+	{8, 8},
+}
+
+// defaultBoardSize is the index of the classic 4x4 board in BoardSizes.
+const defaultBoardSize = 1
+
+// boardSize returns the board size of new games.
+func (a *App) boardSize() BoardSize {
+	return BoardSize{a.prefs.Rows, a.prefs.Columns}
+}
+
+// cycleBoardSize switches to the previous or next entry of BoardSizes when
+// left or right is pressed. Returns true if the size changed.
+// NOTE: A size missing from BoardSizes (set by editing the settings file)
+// is treated as the default one.
+func (a *App) cycleBoardSize() bool {
+	i := slices.Index(BoardSizes, a.boardSize())
+	if i < 0 {
+		i = defaultBoardSize
+	}
+	next := BoardSizes[a.input.cycleOption(i, len(BoardSizes))]
+	if next == a.boardSize() {
+		return false
+	}
+	a.prefs.Rows, a.prefs.Columns = next.Rows, next.Columns
+	return true
+}
+
+// cycleRules switches to the previous or next entry of engine.Variants when
+// left or right is pressed. Returns true if the rules changed.
+func (a *App) cycleRules() bool {
+	i := slices.IndexFunc(engine.Variants, func(r engine.Rules) bool {
+		return r.Name() == a.prefs.Rules
+	})
+	next := engine.Variants[a.input.cycleOption(max(i, 0), len(engine.Variants))]
+	if next.Name() == a.prefs.Rules {
+		return false
+	}
+	a.prefs.Rules = next.Name()
+	return true
+}
+
+// challengeNames are the names of settings.Challenges shown to the player.
+var challengeNames = map[string]string{
+	settings.ChallengeNone:  "None",
+	settings.ChallengeTimed: fmt.Sprintf("%d Minutes", int(settings.TimeAttack.Minutes())),
+	settings.ChallengeMoves: fmt.Sprintf("%d Moves", settings.MoveBudget),
+}
+
+// menuItem is a selectable entry of the main menu.
+type menuItem int
+
+const (
+	menuContinue   menuItem = iota // resume the saved game
+	menuNewGame                    // start a new game with the selected board size
+	menuMode                       // choose the rules of new games
+	menuChallenge                  // choose the challenge of new games
+	menuSettings                   // change the preferences
+	menuHighScores                 // show the high-score table
+	menuReplay                     // watch the replay of the last finished game
+)
+
+// menuItems returns the entries currently shown in the menu.
+func (a *App) menuItems() []menuItem {
+	var items []menuItem
+	if a.hasSave {
+		items = append(items, menuContinue)
+	}
+	items = append(items, menuNewGame, menuMode, menuChallenge, menuSettings, menuHighScores)
+	if a.hasReplays {
+		items = append(items, menuReplay)
+	}
+	return items
+}
+
+// label returns the text shown for a menu entry, with the options of new
+// games taken from the preferences.
+func (m menuItem) label(prefs settings.Settings) string {
+	switch m {
+	case menuContinue:
+		return "Continue"
+	case menuNewGame:
+		return fmt.Sprintf("New Game  < %s >", BoardSize{prefs.Rows, prefs.Columns})
+	case menuMode:
+		return fmt.Sprintf("Mode  < %s >", strings.ToUpper(prefs.Rules[:1])+prefs.Rules[1:])
+	case menuChallenge:
+		return fmt.Sprintf("Challenge  < %s >", challengeNames[prefs.Challenge])
+	case menuSettings:
+		return "Settings"
+	case menuHighScores:
+		return "High Scores"
+	case menuReplay:
+		return "Watch Last Game"
+	}
+	return ""
+}
+
+func drawMenu(screen *ebiten.Image, keys bindings, bestScore int, items []menuItem, selected int, prefs settings.Settings) {
 	// Clear the background
-	screen.Fill(color.RGBA{187, 173, 160, 255})
+	screen.Fill(currentTheme.Background)
 
 	// Title "2048"
 	title := "2048"
 	tw, th := textv2.Measure(title, LargeFace, 0)
-	x := (engine.ScreenWidth - int(tw)) / 2
-	y := engine.ScreenHeight / 4
+	x := view.centerX(tw)
+	y := view.height / 4
 	opts := &textv2.DrawOptions{}
-	opts.GeoM.Translate(float64(x), float64(y))
+	opts.ColorScale.ScaleWithColor(currentTheme.Text)
+	opts.GeoM.Translate(x, y)
 	textv2.Draw(screen, title, LargeFace, opts)
 
-	// Best score display (will default to 0 until load/save)
+	// Best score display
 	bs := fmt.Sprintf("Best Score: %d", bestScore)
 	bw, bh := textv2.Measure(bs, MediumFace, 0)
-	bx := (engine.ScreenWidth - int(bw)) / 2
-	by := y + int(th) + 20
+	bx := view.centerX(bw)
+	by := y + th + view.px(20)
 	bOpts := &textv2.DrawOptions{}
-	bOpts.GeoM.Translate(float64(bx), float64(by))
+	bOpts.ColorScale.ScaleWithColor(currentTheme.Text)
+	bOpts.GeoM.Translate(bx, by)
 	textv2.Draw(screen, bs, MediumFace, bOpts)
 
+	// Menu entries, the selected one is marked with arrows
+	iy := by + bh + view.px(40)
+	for i, item := range items {
+		label := item.label(prefs)
+		if i == selected {
+			label = "> " + label + " <"
+		}
+		iw, ih := textv2.Measure(label, MediumFace, 0)
+		ix := view.centerX(iw)
+		iOpts := &textv2.DrawOptions{}
+		iOpts.ColorScale.ScaleWithColor(currentTheme.Text)
+		iOpts.GeoM.Translate(ix, iy)
+		textv2.Draw(screen, label, MediumFace, iOpts)
+		iy += ih + view.px(16)
+	}
+
 	// Prompt
-	prompt := "Press Enter to Play!"
+	prompt := joinHints(keys.hint("Select", ActionUp, ActionDown), keys.hint("Confirm", ActionConfirm))
 	pw, _ := textv2.Measure(prompt, MediumFace, 0)
-	px := (engine.ScreenWidth - int(pw)) / 2
-	py := by + int(bh) + 40
+	px := view.centerX(pw)
+	py := iy + view.px(24)
 	pOpts := &textv2.DrawOptions{}
-	pOpts.GeoM.Translate(float64(px), float64(py))
+	pOpts.ColorScale.ScaleWithColor(currentTheme.Text)
+	pOpts.GeoM.Translate(px, py)
 	textv2.Draw(screen, prompt, MediumFace, pOpts)
 }
 
 func updateMenu(a *App) {
-	if ebiten.IsKeyPressed(ebiten.KeyEnter) {
-		// Rese the engine and clear any leftover key state
-		a.engine = engine.NewGame()
+	items := a.menuItems()
+	a.menuIndex = min(a.menuIndex, len(items)-1)
+
+	// Up/Down move the selection
+	if a.input.justPressed(ActionUp) && a.menuIndex > 0 {
+		a.menuIndex--
+	}
+	if a.input.justPressed(ActionDown) && a.menuIndex < len(items)-1 {
+		a.menuIndex++
+	}
+
+	item := items[a.menuIndex]
+	if item == menuNewGame && a.cycleBoardSize() {
+		a.savePrefs()
+	}
+	if item == menuMode && a.cycleRules() {
+		a.savePrefs()
+	}
+	if item == menuChallenge {
+		i := slices.Index(settings.Challenges, a.prefs.Challenge)
+		if next := settings.Challenges[a.input.cycleOption(i, len(settings.Challenges))]; next != a.prefs.Challenge {
+			a.prefs.Challenge = next
+			a.savePrefs()
+		}
+	}
+
+	if a.input.justPressed(ActionConfirm) {
+		switch item {
+		case menuContinue:
+			if !a.loadGame() {
+				return // the save is unusable and has been dropped from the menu
+			}
+		case menuNewGame, menuMode, menuChallenge:
+			a.newGame()
+		case menuSettings:
+			a.settingsIndex = 0
+			a.scene = SceneSettings
+			return
+		case menuHighScores:
+			a.scoresIndex = max(slices.Index(settings.Challenges, a.prefs.Challenge), 0)
+			a.scene = SceneHighScores
+			return
+		case menuReplay:
+			if !a.watchLatestReplay() {
+				a.hasReplays = false // unreadable or tampered with
+			}
+			return
+		}
 		a.scene = ScenePlay
 	}
 }
+
+// cycleOption moves the index of an option with n values
+// one step left or right when the matching direction is pressed.
+func (in *input) cycleOption(index, n int) int {
+	if in.justPressed(ActionLeft) && index > 0 {
+		index--
+	}
+	if in.justPressed(ActionRight) && index < n-1 {
+		index++
+	}
+	return index
+}
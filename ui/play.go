@@ -1,123 +1,373 @@
 package ui
 
 import (
-	"image/color"
-	"strconv"
+	"fmt"
+	"strings"
+	"time"
 
 	"2048/engine"
+	"2048/settings"
+	"2048/solver"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	textv2 "github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
-// processArrows handles arrow-key input once per press,
-// applies a move, and returns true if the board changed. (tiles moved or merged)
-func processArrows(a *App) bool {
-	var direction engine.Direction
-	var keyPressed bool
-
-	// Did the user press an arrow key?
-	if inpututil.IsKeyJustPressed(ebiten.KeyArrowLeft) {
-		direction, keyPressed = engine.Left, true
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyArrowRight) {
-		direction, keyPressed = engine.Right, true
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) {
-		direction, keyPressed = engine.Up, true
-	} else if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) {
-		direction, keyPressed = engine.Down, true
-	}
-
-	if keyPressed {
-		if moved, _ := a.engine.Move(direction); moved {
-			return true
+// maxQueuedMoves bounds how many moves can be buffered while animating.
+const maxQueuedMoves = 4
+
+// AutoplayRates lists the selectable autoplay speeds, in moves per second.
+var AutoplayRates = []int{1, 2, 5, 10, 30}
+
+// defaultAutoplayRate is the index of 5 moves per second in AutoplayRates.
+const defaultAutoplayRate = 2
+
+// processHistory handles the undo (also by clicking the UNDO box) and redo actions.
+// Returns true if the board was changed.
+func processHistory(a *App) bool {
+	switch {
+	case a.input.justPressed(ActionRedo):
+		if !a.engine.Redo() {
+			return false
 		}
+		a.recordRedo()
+	case a.input.justPressed(ActionUndo), a.tapped(hudUndo):
+		if !a.engine.Undo() {
+			return false
+		}
+		a.recordUndo()
+	default:
+		return false
+	}
+	return true
+}
+
+// processSolver handles the hint and autoplay actions (with slower/faster to
+// change the rate), and queues a move when autoplay is due to play one.
+func processSolver(a *App) {
+	if a.input.justPressed(ActionHint) {
+		a.hint, a.hasHint = solver.Best(a.engine, a.solver)
+		a.engine.Assisted = a.engine.Assisted || a.hasHint
+	}
+
+	if a.input.justPressed(ActionAutoplay) {
+		a.autoplay = !a.autoplay
+		a.autoWait = 0
+	}
+	if a.input.justPressed(ActionSlower) && a.autoRate > 0 {
+		a.autoRate--
+	}
+	if a.input.justPressed(ActionFaster) && a.autoRate < len(AutoplayRates)-1 {
+		a.autoRate++
+	}
+
+	if !a.autoplay || a.anim != nil || len(a.queued) > 0 {
+		return
+	}
+	if a.autoWait > 0 {
+		a.autoWait--
+		return
+	}
+	if dir, ok := solver.Best(a.engine, a.solver); ok {
+		a.queued = append(a.queued, dir)
+		a.hint, a.hasHint = dir, true // show what the solver is doing
+		a.engine.Assisted = true
+	}
+	a.autoWait = ebiten.TPS() / AutoplayRates[a.autoRate]
+}
+
+// playStatus returns the HUD status text of the play scene: what is left
+// of the time or the moves of a challenge, and the autoplay rate.
+func (a *App) playStatus() string {
+	var status []string
+	switch g := a.engine; {
+	case g.TimeLimit > 0:
+		// NOTE: Rounded up, so the clock reads 0:00 only once time is up
+		status = append(status, "TIME "+formatDuration((g.TimeLeft()+time.Second-1).Truncate(time.Second)))
+	case g.MoveLimit > 0:
+		status = append(status, fmt.Sprintf("MOVES %d", g.MovesLeft()))
+	}
+	if a.autoplay {
+		status = append(status, fmt.Sprintf("AUTO %d/s", AutoplayRates[a.autoRate]))
+	}
+	return strings.Join(status, "  ")
+}
+
+// playMove plays a turn and starts its animation.
+func playMove(a *App, dir engine.Direction) {
+	a.hasHint = false // the hint was for the previous board
+
+	before := cloneBoard(a.engine.Board)
+	events, _ := a.engine.Play(dir)
+	a.recordMove(dir, events)
+	if len(events) > 0 && a.animSpeed != AnimOff {
+		a.anim = newAnimation(before, events, a.animSpeed.frames())
 	}
-	return false
 }
 
 // updatePlay handles game logic for the play scene.
 func updatePlay(a *App) {
-	// Press M at any time to abandon the game and return to menu
-	if ebiten.IsKeyPressed(ebiten.KeyM) {
+	// Press M or click the MENU box at any time to save the game and return
+	// to menu, it can be resumed from there with "Continue"
+	if a.input.justPressed(ActionMenu) || a.tapped(hudMenu) {
+		a.saveGame()
 		a.engine = nil
+		a.anim, a.queued = nil, nil
+		a.autoplay, a.hasHint = false, false
 		a.scene = SceneMenu
 		return
 	}
 
-	if moved := processArrows(a); moved {
-		// If the board changed, spawn a new tile to keep the game going
-		engine.SpawnTile(&a.engine.Board)
+	// Count the time spent in this game, one tick per update
+	// NOTE: Not while the window is in the background, so that the clock
+	// of a timed game stops when the player switches away
+	if ebiten.IsFocused() {
+		a.engine.Elapsed += time.Second / time.Duration(ebiten.TPS())
+	}
+
+	if a.anim != nil && !a.anim.advance() {
+		a.anim = nil
+	}
+
+	// Undo/redo the last turn (the move together with the tile it spawned).
+	// NOTE: This cuts any running animation short and drops buffered moves,
+	// they were meant for the board that is being taken back.
+	if processHistory(a) {
+		a.anim, a.queued = nil, nil
+		a.hasHint = false
+	}
+
+	// Hints and autoplay
+	processSolver(a)
+
+	// Buffer moves instead of dropping them while an animation runs.
+	// Swipes with the mouse or a finger count as arrow keys.
+	dir, ok := a.input.direction()
+	if !ok && a.gesture.kind == gestureSwipe {
+		dir, ok = a.gesture.dir, true
+	}
+	if ok && len(a.queued) < maxQueuedMoves {
+		a.queued = append(a.queued, dir)
+	}
+	if a.anim != nil {
+		return // wait for the tiles to land
 	}
 
-	if !a.engine.CanMove() {
-		// end of game
-		if a.engine.Score > a.bestScore {
-			a.bestScore = a.engine.Score
+	// Play a turn; the engine spawns the new tile itself when the board changed
+	if len(a.queued) > 0 {
+		dir := a.queued[0]
+		a.queued = a.queued[1:]
+		playMove(a, dir)
+		if a.anim != nil {
+			return // check the outcome once the animation is over
 		}
+	}
+
+	if a.engine.JustWon() {
+		// Let the player choose between keeping going and a new game
+		a.queued = nil
+		a.autoplay, a.hasHint = false, false
+		a.winIndex = 0
+		a.scene = SceneWin
+		return
+	}
+
+	if _, over := a.engine.Over(); over {
+		// end of game: stuck, or out of time or moves
+		a.queued = nil
+		a.autoplay, a.hasHint = false, false
+		a.recordScore()
+		a.deleteSave()
 		a.scene = SceneGameOver
 	}
 }
 
-// drawPlay renders the game board and HUD.
-func drawPlay(screen *ebiten.Image, g *engine.Game) {
-	// Background for the board area (starts below the HUD)
-	boardBg := color.RGBA{187, 173, 160, 255}
+// cloneBoard returns a deep copy of a board.
+func cloneBoard(board [][]int) [][]int {
+	out := make([][]int, len(board))
+	for r := range board {
+		out[r] = append([]int(nil), board[r]...)
+	}
+	return out
+}
+
+// boardLayout holds the on-screen geometry of the board.
+type boardLayout struct {
+	tileSize  float64 // size of a cell, margins included
+	offsetX   float64 // left edge of the grid
+	offsetY   float64 // top edge of the grid
+	innerSize float64 // size of a tile, margins excluded
+}
+
+// newBoardLayout computes tile dimensions relative to the board area.
+// NOTE: Tiles stay square, so the board is centered along the longer axis
+// when the grid or the board area isn't square.
+func newBoardLayout(g *engine.Game) boardLayout {
+	area := view.board
+	tileSize := min(area.w/float64(g.Columns), area.h/float64(g.Rows))
+	margin := min(view.px(8), tileSize/8) // gap around each tile
+	return boardLayout{
+		tileSize:  tileSize,
+		offsetX:   area.x + (area.w-tileSize*float64(g.Columns))/2,
+		offsetY:   area.y + (area.h-tileSize*float64(g.Rows))/2,
+		innerSize: tileSize - 2*margin,
+	}
+}
+
+// cellCenter returns the screen position of the center of a cell.
+// Fractional rows and columns are allowed for tiles in motion.
+func (l boardLayout) cellCenter(row, column float64) (float64, float64) {
+	return column*l.tileSize + l.tileSize/2 + l.offsetX,
+		row*l.tileSize + l.tileSize/2 + l.offsetY
+}
+
+// drawPlay renders the game board, animating the last move if anim is set.
+func drawPlay(screen *ebiten.Image, g *engine.Game, anim *animation) {
+	// Background for the board area (below the HUD, or right of it)
+	area := view.board
 	vector.DrawFilledRect(screen,
-		0,
-		float32(HUDHeight), // Start Y at HUDHeight
-		float32(engine.ScreenWidth),
-		float32(engine.ScreenHeight-HUDHeight),
-		boardBg,
+		float32(area.x), float32(area.y),
+		float32(area.w), float32(area.h),
+		currentTheme.Background,
 		false)
 
-	// Compute tile dimensions relative to the new board area
-	boardSize := float64(engine.ScreenHeight - HUDHeight)
-	tileSize := boardSize / float64(engine.GridN)
-	margin := 8.0
-	innerSize := tileSize - 2*margin
+	l := newBoardLayout(g)
 
-	for r := 0; r < engine.GridN; r++ {
-		for c := 0; c < engine.GridN; c++ {
-			// Calculate position of the empty cell background
-			cellX := float64(c)*tileSize + margin
-			cellY := float64(r)*tileSize + margin + HUDHeight
+	// Draw the background for every cell first
+	for r := 0; r < g.Rows; r++ {
+		for c := 0; c < g.Columns; c++ {
+			cx, cy := l.cellCenter(float64(r), float64(c))
+			drawTile(screen, g.Rules, cx, cy, l.innerSize, 0, 1)
+		}
+	}
 
-			// Draw the background for an empty cell first
-			vector.DrawFilledRect(screen,
-				float32(cellX), float32(cellY),
-				float32(innerSize), float32(innerSize),
-				TileColors[0].Background, false)
+	if anim != nil {
+		if sliding, p := anim.sliding(); sliding {
+			// Draw the tiles of the previous board on their way to their destination
+			for r := range anim.before {
+				for c, v := range anim.before[r] {
+					if v == 0 {
+						continue
+					}
+					to, ok := anim.targets[engine.Cell{Row: r, Column: c}]
+					if !ok {
+						to = engine.Cell{Row: r, Column: c} // the tile stays in place
+					}
+					row := float64(r) + (float64(to.Row)-float64(r))*p
+					column := float64(c) + (float64(to.Column)-float64(c))*p
+					cx, cy := l.cellCenter(row, column)
+					drawTile(screen, g.Rules, cx, cy, l.innerSize, v, 1)
+				}
+			}
+			return
+		}
+	}
 
+	for r := 0; r < g.Rows; r++ {
+		for c := 0; c < g.Columns; c++ {
 			v := g.Board[r][c]
 			if v == 0 {
 				continue // Skip drawing number for empty tiles
 			}
 
-			// A tile with a value exists, so draw its specific background over the empty cell
-			colors := TileColors[v]
-			vector.DrawFilledRect(screen,
-				float32(cellX), float32(cellY),
-				float32(innerSize), float32(innerSize),
-				colors.Background, false)
-
-			// Draw the number on the tile with perfect centering
-			s := strconv.Itoa(v)
-			// Use the font's metrics to get accurate dimensions for centering
-			boundsX, boundsY := textv2.Measure(s, LargeFace, LargeFace.Metrics().CapHeight)
-
-			// Calculate position to center the text inside the tile
-			px := cellX + (innerSize-boundsX)/2
-			py := cellY + (innerSize+boundsY)/2 // This formula correctly centers vertically
-
-			opts := &textv2.DrawOptions{}
-			opts.GeoM.Translate(px, py)
-			opts.ColorScale.SetR(float32(colors.Foreground.R) / 255.0)
-			opts.ColorScale.SetG(float32(colors.Foreground.G) / 255.0)
-			opts.ColorScale.SetB(float32(colors.Foreground.B) / 255.0)
-			opts.ColorScale.SetA(float32(colors.Foreground.A) / 255.0)
-			textv2.Draw(screen, s, LargeFace, opts)
+			scale := 1.0
+			if anim != nil {
+				scale = anim.scale(engine.Cell{Row: r, Column: c})
+			}
+			cx, cy := l.cellCenter(float64(r), float64(c))
+			drawTile(screen, g.Rules, cx, cy, l.innerSize, v, scale)
 		}
 	}
 }
+
+// drawTile draws a tile of value v (0 for an empty cell, engine.Wall for a
+// wall) centered on (cx, cy),
+// scaled by scale for animations. Its color follows the rank of v in rules.
+func drawTile(screen *ebiten.Image, rules engine.Rules, cx, cy, size float64, v int, scale float64) {
+	if scale <= 0 {
+		return
+	}
+	size *= scale
+	cellX, cellY := cx-size/2, cy-size/2
+
+	if v == engine.Wall {
+		// NOTE: Walls take the color of the HUD boxes, which themes keep
+		// apart from the board and the tiles
+		vector.DrawFilledRect(screen,
+			float32(cellX), float32(cellY),
+			float32(size), float32(size),
+			currentTheme.Widget, false)
+		return
+	}
+
+	colors := currentTheme.Tile(rules.Rank(v))
+	vector.DrawFilledRect(screen,
+		float32(cellX), float32(cellY),
+		float32(size), float32(size),
+		colors.Background, false)
+	if v == 0 {
+		return
+	}
+
+	// Draw the number on the tile with perfect centering
+	s := settings.FormatTile(v, tileNotation)
+	// Use the font's metrics to get accurate dimensions for centering
+	boundsX, boundsY := textv2.Measure(s, LargeFace, LargeFace.Metrics().CapHeight)
+
+	// Long numbers shrink to fit the tile, e.g. on large boards
+	// NOTE: size is already scaled, so the fit is computed on the full tile
+	textScale := scale * fitScale(boundsX, boundsY, size/scale*tileTextRatio, size/scale*tileTextRatio)
+
+	// Calculate position to center the text inside the tile
+	// NOTE: Text is drawn from the top of its line, not from its baseline
+	px := cx - boundsX*textScale/2
+	py := cy - boundsY*textScale/2
+
+	opts := &textv2.DrawOptions{}
+	opts.GeoM.Scale(textScale, textScale)
+	opts.GeoM.Translate(px, py)
+	opts.Filter = ebiten.FilterLinear
+	opts.ColorScale.ScaleWithColor(colors.Foreground)
+	textv2.Draw(screen, s, LargeFace, opts)
+}
+
+// tileTextRatio is the largest share of a tile's width its number may take.
+const tileTextRatio = 0.85
+
+// fitScale returns the scale that shrinks text of the given size to fit
+// within maxWidth and maxHeight. Text that already fits keeps its size.
+func fitScale(width, height, maxWidth, maxHeight float64) float64 {
+	scale := 1.0
+	if width > maxWidth {
+		scale = maxWidth / width
+	}
+	if height*scale > maxHeight {
+		scale = maxHeight / height
+	}
+	return scale
+}
+
+// drawHint highlights the edge of the board the suggested move slides towards.
+func drawHint(screen *ebiten.Image, g *engine.Game, dir engine.Direction) {
+	l := newBoardLayout(g)
+	left, top := l.offsetX, l.offsetY
+	width, height := l.tileSize*float64(g.Columns), l.tileSize*float64(g.Rows)
+	thickness := view.px(10)
+
+	x, y, w, h := left, top, width, height
+	switch dir {
+	case engine.Left:
+		w = thickness
+	case engine.Right:
+		x, w = left+width-thickness, thickness
+	case engine.Up:
+		h = thickness
+	case engine.Down:
+		y, h = top+height-thickness, thickness
+	}
+
+	hintCol := currentTheme.Accent
+	hintCol.A = 200
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(w), float32(h), hintCol, false)
+}
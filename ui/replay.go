@@ -0,0 +1,239 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"2048/engine"
+	"2048/replay"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// ReplaySpeeds lists the selectable playback speeds, relative to real time.
+var ReplaySpeeds = []float64{0.5, 1, 2, 4, 8}
+
+// defaultReplaySpeed is the index of real time in ReplaySpeeds.
+const defaultReplaySpeed = 1
+
+// maxReplayPause caps the wait between two steps during playback,
+// so that long breaks of the player don't stall the replay.
+const maxReplayPause = time.Second
+
+// Geometry of the progress bar, drawn at the bottom of the HUD,
+// in design pixels.
+const (
+	progressMargin = 20 // from the sides of the screen
+	progressBottom = 10 // from the top of the bar to the bottom of the HUD
+	progressHeight = 6
+)
+
+// progressRect returns the area of the progress bar: along the bottom of
+// the HUD, or above the Menu box in landscape.
+func progressRect() rect {
+	height := view.px(progressHeight)
+	if view.landscape {
+		menu := hudMenu.rect()
+		return rect{menu.x, menu.y - view.px(progressBottom) - height, menu.w, height}
+	}
+	margin := view.px(progressMargin)
+	return rect{margin, view.hud.h - view.px(progressBottom), view.width - 2*margin, height}
+}
+
+// replayPlayer plays back a recorded game.
+type replayPlayer struct {
+	frames  []replay.Frame // state after each step, frame 0 is the initial board
+	index   int            // frame shown
+	playing bool
+	speed   int           // index into ReplaySpeeds
+	wait    time.Duration // play time left before the next step
+	anim    *animation    // animation of the last step, nil when idle
+	back    Scene         // scene to return to
+
+	scrubbing bool // the progress bar is being dragged
+}
+
+// watchReplay verifies a replay and switches to the replay scene.
+// Returns false if the replay doesn't match the engine, e.g. was tampered with.
+func (a *App) watchReplay(r *replay.Replay) bool {
+	frames, err := r.Frames()
+	if err != nil {
+		log.Println("replay:", err)
+		return false
+	}
+	a.player = &replayPlayer{frames: frames, speed: defaultReplaySpeed, playing: true, back: a.scene}
+	a.scene = SceneReplay
+	return true
+}
+
+// watchLatestReplay plays the replay of the last finished game.
+func (a *App) watchLatestReplay() bool {
+	if a.store == nil {
+		return false
+	}
+	names, err := a.store.ListReplays()
+	if err != nil || len(names) == 0 {
+		log.Println("listing replays:", err)
+		return false
+	}
+	r, err := a.store.LoadReplay(names[0])
+	if err != nil {
+		log.Println("loading replay:", err)
+		return false
+	}
+	return a.watchReplay(r)
+}
+
+// recordMove adds a played turn to the replay of the current game.
+// NOTE: Games resumed from a save made before replays existed aren't recorded.
+func (a *App) recordMove(dir engine.Direction, events []engine.Event) {
+	if a.replay != nil {
+		a.replay.RecordMove(dir, events, a.engine.Elapsed)
+	}
+}
+
+// recordUndo adds an undo to the replay of the current game.
+func (a *App) recordUndo() {
+	if a.replay != nil {
+		a.replay.RecordUndo(a.engine.Elapsed)
+	}
+}
+
+// recordRedo adds a redo to the replay of the current game.
+func (a *App) recordRedo() {
+	if a.replay != nil {
+		a.replay.RecordRedo(a.engine.Elapsed)
+	}
+}
+
+// updateReplay handles the playback controls: confirm plays/pauses,
+// left/right step, up/down or slower/faster change the speed, the seek
+// actions jump, clicking or dragging the progress bar seeks, and back or
+// menu leave.
+func updateReplay(a *App) {
+	p := a.player
+	in := a.input
+	if in.justPressed(ActionBack) || in.justPressed(ActionMenu) {
+		a.player = nil
+		a.scene = p.back
+		return
+	}
+
+	if p.anim != nil && !p.anim.advance() {
+		p.anim = nil
+	}
+
+	last := len(p.frames) - 1
+	jump := max(1, len(p.frames)/10)
+	switch {
+	case in.justPressed(ActionConfirm):
+		if p.index == last {
+			p.seek(0) // play again from the start
+		}
+		p.playing = !p.playing
+	case in.justPressed(ActionRight):
+		p.playing = false
+		p.step(a.animSpeed)
+	case in.justPressed(ActionLeft):
+		p.playing = false
+		p.seek(p.index - 1)
+	case in.justPressed(ActionUp), in.justPressed(ActionFaster):
+		p.speed = min(p.speed+1, len(ReplaySpeeds)-1)
+	case in.justPressed(ActionDown), in.justPressed(ActionSlower):
+		p.speed = max(p.speed-1, 0)
+	case in.justPressed(ActionSeekStart):
+		p.seek(0)
+	case in.justPressed(ActionSeekEnd):
+		p.seek(last)
+	case in.justPressed(ActionSeekBack):
+		p.seek(p.index - jump)
+	case in.justPressed(ActionSeekForward):
+		p.seek(p.index + jump)
+	}
+
+	// Scrubbing: once grabbed, the progress bar follows the mouse until released
+	x, y := ebiten.CursorPosition()
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && onProgressBar(x, y) {
+		p.scrubbing = true
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		p.scrubbing = false
+	}
+	if p.scrubbing {
+		bar := progressRect()
+		f := min(max((float64(x)-bar.x)/bar.w, 0), 1)
+		p.seek(int(f*float64(last) + 0.5))
+		return
+	}
+
+	if !p.playing || p.anim != nil {
+		return
+	}
+	p.wait -= time.Duration(float64(time.Second) / float64(ebiten.TPS()) * ReplaySpeeds[p.speed])
+	if p.wait > 0 {
+		return
+	}
+	if p.index == last {
+		p.playing = false
+		return
+	}
+	p.step(a.animSpeed)
+}
+
+// onProgressBar reports whether a screen position is on the progress bar,
+// with some slack above and below so it is easy to grab.
+func onProgressBar(x, y int) bool {
+	bar := progressRect()
+	slack := rect{bar.x - view.px(5), bar.y - view.px(8), bar.w + view.px(10), bar.h + view.px(12)}
+	return slack.contains(x, y)
+}
+
+// step moves one step forward, animating it if it was a move.
+func (p *replayPlayer) step(speed AnimSpeed) {
+	if p.index == len(p.frames)-1 {
+		return
+	}
+	before := p.frames[p.index].Game.Board
+	p.index++
+	next := p.frames[p.index]
+	p.anim = nil
+	if next.Events != nil && speed != AnimOff {
+		p.anim = newAnimation(before, next.Events, speed.frames())
+	}
+	if p.index < len(p.frames)-1 {
+		p.wait = min(p.frames[p.index+1].At-next.At, maxReplayPause)
+	}
+}
+
+// seek shows the given frame without animating.
+func (p *replayPlayer) seek(index int) {
+	p.index = min(max(index, 0), len(p.frames)-1)
+	p.anim, p.wait = nil, 0
+}
+
+// status returns the HUD status text of the replay scene.
+func (p *replayPlayer) status() string {
+	state := "PAUSED"
+	if p.playing {
+		state = "PLAY"
+	}
+	return fmt.Sprintf("%s %gx  %d/%d", state, ReplaySpeeds[p.speed], p.index, len(p.frames)-1)
+}
+
+// drawReplay renders the frame being shown, the HUD and the progress bar.
+func drawReplay(screen *ebiten.Image, keys bindings, p *replayPlayer, best int) {
+	frame := p.frames[p.index]
+	drawPlay(screen, frame.Game, p.anim)
+	drawHUD(screen, keys, frame.Game, best, frame.Undos, p.status())
+
+	bar := progressRect()
+	done := bar.w
+	if last := len(p.frames) - 1; last > 0 {
+		done = bar.w * float64(p.index) / float64(last)
+	}
+	vector.DrawFilledRect(screen, float32(bar.x), float32(bar.y), float32(bar.w), float32(bar.h), currentTheme.Widget, false)
+	vector.DrawFilledRect(screen, float32(bar.x), float32(bar.y), float32(done), float32(bar.h), currentTheme.Accent, false)
+}
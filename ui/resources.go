@@ -3,9 +3,11 @@ package ui
 
 import (
 	_ "embed"
-	"image/color"
 	"log"
 
+	"2048/settings"
+	"2048/theme"
+
 	textv2 "github.com/hajimehoshi/ebiten/v2/text/v2"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
@@ -19,64 +21,85 @@ var (
 	MediumFace textv2.Face // medium numbers, small titles, etc.
 )
 
-// TileColors maps tile values to their background and text colors.
-var TileColors map[int]struct {
-	Background color.RGBA
-	Foreground color.RGBA
-}
+// currentTheme holds the colors everything is drawn with, see setTheme.
+var currentTheme = theme.Classic
+
+// Font the faces are made from, and the layout unit they are scaled by.
+var (
+	faceTTF  = protoTTF
+	faceUnit = 1.0
+)
+
+// tileNotation is how large tile values are written, one of settings.Notations.
+var tileNotation = settings.NumbersPlain
 
 func init() {
-	// Load the TTF
-	tt, err := opentype.Parse(protoTTF)
+	var err error
+	LargeFace, MediumFace, err = loadFaces(protoTTF, faceUnit)
 	if err != nil {
-		log.Fatal("parsing proto TTF:", err)
+		log.Fatal("loading proto TTF:", err)
 	}
+}
 
-	// Create the base face (used for measuring)
-	const dpi = 72
+// loadFaces creates the large and medium faces from a TTF or OTF file,
+// with their sizes multiplied by unit (see screenLayout).
+func loadFaces(ttf []byte, unit float64) (large, medium textv2.Face, err error) {
+	tt, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	largeFontBaseFace, err := opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    32,
+	const dpi = 72
+	largeBaseFace, err := opentype.NewFace(tt, &opentype.FaceOptions{
+		Size:    32 * unit,
 		DPI:     dpi,
 		Hinting: font.HintingFull,
 	})
 	if err != nil {
-		log.Fatal("creating base LargeFace:", err)
+		return nil, nil, err
 	}
-
-	LargeFace = textv2.NewGoXFace(largeFontBaseFace)
-
-	MediumFontBaseFace, err := opentype.NewFace(tt, &opentype.FaceOptions{
-		Size:    18,
+	mediumBaseFace, err := opentype.NewFace(tt, &opentype.FaceOptions{
+		Size:    18 * unit,
 		DPI:     dpi,
 		Hinting: font.HintingFull,
 	})
 	if err != nil {
-		log.Fatal("creating MediumFace:", err)
+		return nil, nil, err
 	}
+	return textv2.NewGoXFace(largeBaseFace), textv2.NewGoXFace(mediumBaseFace), nil
+}
 
-	MediumFace = textv2.NewGoXFace(MediumFontBaseFace)
+// setTheme switches to a theme and its font.
+// A font that fails to load is replaced by the built-in one.
+func setTheme(t theme.Theme) {
+	currentTheme = t
 
-	// It's good practice to define foreground (text) color along with background.
-	// Light numbers (2, 4) have dark text, darker tiles have light text.
-	fgDark := color.RGBA{119, 110, 101, 255}
-	fgLight := color.RGBA{249, 246, 242, 255}
+	faceTTF = protoTTF
+	if len(t.FontData) > 0 {
+		faceTTF = t.FontData
+	}
+	large, medium, err := loadFaces(faceTTF, faceUnit)
+	if err != nil {
+		log.Printf("loading font of theme %s: %v", t.Name, err)
+		faceTTF = protoTTF
+		large, medium, err = loadFaces(faceTTF, faceUnit)
+		if err != nil {
+			log.Fatal("loading proto TTF:", err)
+		}
+	}
+	LargeFace, MediumFace = large, medium
+}
 
-	TileColors = map[int]struct {
-		Background color.RGBA
-		Foreground color.RGBA
-	}{
-		0:    {Background: color.RGBA{205, 193, 180, 255}, Foreground: fgDark},
-		2:    {Background: color.RGBA{238, 228, 218, 255}, Foreground: fgDark},
-		4:    {Background: color.RGBA{237, 224, 200, 255}, Foreground: fgDark},
-		8:    {Background: color.RGBA{242, 177, 121, 255}, Foreground: fgLight},
-		16:   {Background: color.RGBA{245, 149, 99, 255}, Foreground: fgLight},
-		32:   {Background: color.RGBA{246, 124, 95, 255}, Foreground: fgLight},
-		64:   {Background: color.RGBA{246, 94, 59, 255}, Foreground: fgLight},
-		128:  {Background: color.RGBA{237, 207, 114, 255}, Foreground: fgLight},
-		256:  {Background: color.RGBA{237, 204, 97, 255}, Foreground: fgLight},
-		512:  {Background: color.RGBA{237, 200, 80, 255}, Foreground: fgLight},
-		1024: {Background: color.RGBA{237, 197, 63, 255}, Foreground: fgLight},
-		2048: {Background: color.RGBA{237, 194, 46, 255}, Foreground: fgLight},
+// scaleFaces resizes the faces for a new layout unit.
+func scaleFaces(unit float64) {
+	if unit == faceUnit || unit <= 0 {
+		return
+	}
+	large, medium, err := loadFaces(faceTTF, unit)
+	if err != nil {
+		log.Println("scaling fonts:", err) // the font loaded before, so this can't happen
+		return
 	}
+	faceUnit = unit
+	LargeFace, MediumFace = large, medium
 }
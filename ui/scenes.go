@@ -6,4 +6,9 @@ const (
 	SceneMenu Scene = iota
 	ScenePlay
 	SceneGameOver
+	SceneHighScores
+	SceneWin
+	SceneReplay
+	SceneControls
+	SceneSettings
 )
@@ -0,0 +1,212 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+
+	"2048/engine"
+	"2048/settings"
+	"2048/theme"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	textv2 "github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// settingsRow is an entry of the settings screen.
+type settingsRow int
+
+const (
+	settingBoardSize  settingsRow = iota // board size of new games
+	settingAnimation                     // animation speed
+	settingTheme                         // color theme
+	settingVolume                        // sound volume, stored for when the game plays sounds
+	settingFourChance                    // spawn odds, for practice
+	settingWalls                         // random walls on new boards
+	settingUndoLimit                     // turns that can be undone
+	settingUndoReroll                    // undone turns may spawn another tile, for practice
+	settingNumbers                       // notation of large tile values
+	settingFullscreen                    // window or fullscreen
+	settingControls                      // opens the rebinding screen
+	settingsRowCount
+)
+
+// FourChances lists the selectable probabilities of spawning a 4.
+var FourChances = []float64{0, 0.05, engine.DefaultFourChance, 0.25, 0.5, 1}
+
+// volumeStep is how much Left/Right change the volume.
+const volumeStep = 0.1
+
+// settingsRows returns the text of every row of the settings screen.
+func (a *App) settingsRows() []string {
+	rows := make([]string, settingsRowCount)
+	rows[settingBoardSize] = fmt.Sprintf("Board Size  < %s >", a.boardSize())
+	rows[settingAnimation] = fmt.Sprintf("Animations  < %s >", a.animSpeed)
+	rows[settingTheme] = fmt.Sprintf("Theme  < %s >", strings.ToUpper(a.prefs.Theme[:1])+a.prefs.Theme[1:])
+	rows[settingVolume] = fmt.Sprintf("Volume  < %.0f%% >", a.prefs.Volume*100)
+	rows[settingFourChance] = fmt.Sprintf("Chance of 4s  < %g%% >", a.prefs.FourChance*100)
+	if a.prefs.FourChance != engine.DefaultFourChance {
+		rows[settingFourChance] += "  (practice)"
+	}
+	rows[settingWalls] = "Walls  < Off >"
+	if a.prefs.Walls > 0 {
+		rows[settingWalls] = fmt.Sprintf("Walls  < %d >", a.prefs.Walls)
+	}
+	rows[settingUndoLimit] = "Undo Limit  < Off >"
+	if a.prefs.UndoLimit > 0 {
+		rows[settingUndoLimit] = fmt.Sprintf("Undo Limit  < %d >", a.prefs.UndoLimit)
+	}
+	rows[settingUndoReroll] = "Undo Reroll  < Off >"
+	if a.prefs.UndoReroll {
+		rows[settingUndoReroll] = "Undo Reroll  < On >  (practice)"
+	}
+	rows[settingNumbers] = fmt.Sprintf("Large Numbers  < %s >", settings.FormatTile(1<<17, a.prefs.Numbers))
+	rows[settingFullscreen] = "Fullscreen  < Off >"
+	if a.prefs.Fullscreen {
+		rows[settingFullscreen] = "Fullscreen  < On >"
+	}
+	rows[settingControls] = "Controls"
+	return rows
+}
+
+// updateSettings lets the player change the highlighted setting with
+// left/right. Changes apply at once and are saved when leaving.
+func updateSettings(a *App) {
+	in := a.input
+	if in.justPressed(ActionBack) || in.justPressed(ActionMenu) {
+		a.savePrefs()
+		a.scene = SceneMenu
+		return
+	}
+
+	if in.justPressed(ActionUp) && a.settingsIndex > 0 {
+		a.settingsIndex--
+	}
+	if in.justPressed(ActionDown) && a.settingsIndex < int(settingsRowCount)-1 {
+		a.settingsIndex++
+	}
+
+	before := a.prefs
+	switch settingsRow(a.settingsIndex) {
+	case settingBoardSize:
+		a.cycleBoardSize()
+	case settingAnimation:
+		i := slices.Index(settings.AnimationSpeeds, a.prefs.Animation)
+		a.prefs.Animation = settings.AnimationSpeeds[in.cycleOption(i, len(settings.AnimationSpeeds))]
+	case settingTheme:
+		i := max(slices.IndexFunc(a.themes, func(t theme.Theme) bool { return t.Name == a.prefs.Theme }), 0)
+		a.prefs.Theme = a.themes[in.cycleOption(i, len(a.themes))].Name
+	case settingVolume:
+		// NOTE: Kept in tenths, so repeated steps don't drift
+		steps := int(math.Round(a.prefs.Volume / volumeStep))
+		a.prefs.Volume = float64(in.cycleOption(steps, int(1/volumeStep)+1)) * volumeStep
+	case settingFourChance:
+		i := slices.Index(FourChances, a.prefs.FourChance)
+		if i < 0 {
+			i = slices.Index(FourChances, engine.DefaultFourChance)
+		}
+		a.prefs.FourChance = FourChances[in.cycleOption(i, len(FourChances))]
+	case settingWalls:
+		a.prefs.Walls = in.cycleOption(a.prefs.Walls, settings.MaxWalls+1)
+	case settingUndoLimit:
+		i := slices.Index(settings.UndoLimits, a.prefs.UndoLimit)
+		if i < 0 {
+			i = slices.Index(settings.UndoLimits, engine.DefaultHistoryLimit)
+		}
+		a.prefs.UndoLimit = settings.UndoLimits[in.cycleOption(i, len(settings.UndoLimits))]
+	case settingUndoReroll:
+		if in.justPressed(ActionLeft) || in.justPressed(ActionRight) || in.justPressed(ActionConfirm) {
+			a.prefs.UndoReroll = !a.prefs.UndoReroll
+		}
+	case settingNumbers:
+		i := slices.Index(settings.Notations, a.prefs.Numbers)
+		a.prefs.Numbers = settings.Notations[in.cycleOption(i, len(settings.Notations))]
+	case settingFullscreen:
+		if in.justPressed(ActionLeft) || in.justPressed(ActionRight) || in.justPressed(ActionConfirm) {
+			a.prefs.Fullscreen = !a.prefs.Fullscreen
+		}
+	case settingControls:
+		if in.justPressed(ActionConfirm) {
+			a.openControls()
+			return
+		}
+	}
+
+	// NOTE: Only a changed value is applied, so the bindings aren't rebuilt
+	// every frame and fullscreen toggled elsewhere (e.g. F11) isn't undone.
+	if !reflect.DeepEqual(a.prefs, before) {
+		a.applyPrefs()
+	}
+}
+
+// applyPrefs puts the preferences into effect.
+// The board size, spawn odds, walls and undo settings are read when the next
+// game starts.
+func (a *App) applyPrefs() {
+	a.animSpeed = AnimSpeeds[max(slices.Index(settings.AnimationSpeeds, a.prefs.Animation), 0)]
+	tileNotation = a.prefs.Numbers
+	if ebiten.IsFullscreen() != a.prefs.Fullscreen {
+		ebiten.SetFullscreen(a.prefs.Fullscreen)
+	}
+
+	// NOTE: Compared by name, as reloading the font on every update of the
+	// settings screen would be slow. Unknown themes fall back to Classic.
+	if t := theme.Find(a.themes, a.prefs.Theme); t.Name != currentTheme.Name {
+		setTheme(t)
+	}
+
+	// Broken entries are skipped, the rest of the bindings still apply
+	a.input.bindings = defaultBindings()
+	for _, err := range a.input.bindings.apply(a.prefs.Keys, a.prefs.Gamepad) {
+		log.Println(err)
+	}
+	for _, c := range a.input.bindings.conflicts() {
+		log.Println("bindings:", c)
+	}
+}
+
+// savePrefs writes the preferences to the settings file.
+func (a *App) savePrefs() {
+	if a.store == nil {
+		return
+	}
+	if err := a.store.SaveSettings(a.prefs); err != nil {
+		log.Println("saving settings:", err)
+	}
+}
+
+// drawSettings renders the settings screen like the menu.
+func drawSettings(screen *ebiten.Image, keys bindings, rows []string, selected int) {
+	screen.Fill(currentTheme.Background)
+
+	title := "Settings"
+	tw, th := textv2.Measure(title, LargeFace, 0)
+	tOpts := &textv2.DrawOptions{}
+	tOpts.ColorScale.ScaleWithColor(currentTheme.Text)
+	// Higher than the menu title, to leave room for every row
+	tOpts.GeoM.Translate(view.centerX(tw), view.height/8)
+	textv2.Draw(screen, title, LargeFace, tOpts)
+
+	y := view.height/8 + th + view.px(40)
+	for i, row := range rows {
+		if i == selected {
+			row = "> " + row + " <"
+		}
+		w, h := textv2.Measure(row, MediumFace, 0)
+		opts := &textv2.DrawOptions{}
+		opts.ColorScale.ScaleWithColor(currentTheme.Text)
+		opts.GeoM.Translate(view.centerX(w), y)
+		textv2.Draw(screen, row, MediumFace, opts)
+		y += h + view.px(16)
+	}
+
+	prompt := joinHints(keys.hint("Change", ActionLeft, ActionRight), keys.hint("Back", ActionBack))
+	pw, _ := textv2.Measure(prompt, MediumFace, 0)
+	pOpts := &textv2.DrawOptions{}
+	pOpts.ColorScale.ScaleWithColor(currentTheme.Text)
+	pOpts.GeoM.Translate(view.centerX(pw), y+view.px(24))
+	textv2.Draw(screen, prompt, MediumFace, pOpts)
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	textv2 "github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// winOptions are the choices offered once the target tile is reached.
+var winOptions = []string{"Keep going", "New game"}
+
+func updateWin(a *App) {
+	// Up/Down move the selection
+	if a.input.justPressed(ActionUp) && a.winIndex > 0 {
+		a.winIndex--
+	}
+	if a.input.justPressed(ActionDown) && a.winIndex < len(winOptions)-1 {
+		a.winIndex++
+	}
+
+	if !a.input.justPressed(ActionConfirm) {
+		return
+	}
+	switch a.winIndex {
+	case 0:
+		// Continue the same game, the overlay won't show up again
+		a.engine.KeepGoing()
+	case 1:
+		// The won game counts as finished
+		a.recordScore()
+		a.deleteSave()
+		a.newGame()
+	}
+	a.scene = ScenePlay
+}
+
+// drawWin overlays a translucent backdrop with the win message and options.
+func drawWin(screen *ebiten.Image, target, selected int) {
+	// Golden overlay over the board, leaving the HUD visible
+	overlayCol := color.RGBA{237, 194, 46, 150}
+	area := view.board
+	vector.DrawFilledRect(screen,
+		float32(area.x), float32(area.y),
+		float32(area.w), float32(area.h),
+		overlayCol, false)
+
+	// "You Win!" title
+	title := "You Win!"
+	tw, th := textv2.Measure(title, LargeFace, 0)
+	tx := area.x + (area.w-tw)/2
+	ty := area.y + area.h/3
+	topts := &textv2.DrawOptions{}
+	topts.GeoM.Translate(tx, ty)
+	textv2.Draw(screen, title, LargeFace, topts)
+
+	// Which tile was reached
+	msg := fmt.Sprintf("You reached the %d tile", target)
+	mw, mh := textv2.Measure(msg, MediumFace, 0)
+	mx := area.x + (area.w-mw)/2
+	my := ty + th + view.px(20)
+	mopts := &textv2.DrawOptions{}
+	mopts.GeoM.Translate(mx, my)
+	textv2.Draw(screen, msg, MediumFace, mopts)
+
+	// Options, the selected one is marked with arrows
+	oy := my + mh + view.px(40)
+	for i, option := range winOptions {
+		if i == selected {
+			option = "> " + option + " <"
+		}
+		ow, oh := textv2.Measure(option, MediumFace, 0)
+		ox := area.x + (area.w-ow)/2
+		oopts := &textv2.DrawOptions{}
+		oopts.GeoM.Translate(ox, oy)
+		textv2.Draw(screen, option, MediumFace, oopts)
+		oy += oh + view.px(16)
+	}
+}